@@ -1,17 +1,19 @@
 package animation
 
 import (
+	"fmt"
 	"image"
 	"io"
 	"os"
 	"path/filepath"
 	"slices"
-	"time"
 
 	yaml "gopkg.in/yaml.v3"
 )
 
 type Part struct {
+	// Layer names the part for per-entity appearance changes, eg. "helmet"
+	Layer          string `json:"layer,omitempty"`
 	Tileset        string `json:"tileset"`
 	TileID         int    `json:"tile_id"`
 	XOffset        int    `json:"x_offset"`
@@ -21,11 +23,16 @@ type Part struct {
 }
 
 type Frame struct {
-	Duration int    `json:"duration"`
-	Parts    []Part `json:"parts"`
+	Duration int     `json:"duration"`
+	Parts    []Part  `json:"parts"`
+	Events   []Event `json:"events,omitempty"`
 }
 
+// Animation holds the frames of an animation. It is shared by every entity using the animation,
+// playback state lives in an AnimationPlayer.
 type Animation struct {
+	// Source is the file the animation was loaded from
+	Source       string   `yaml:"-" json:"-"`
 	Class        string   `yaml:"class" json:"class"`
 	Action       string   `yaml:"action" json:"action"`
 	TilesetGroup string   `yaml:"tileset_group" json:"tileset_group"`
@@ -34,48 +41,32 @@ type Animation struct {
 	Simple       *simple  `yaml:"simple,omitempty" json:"simple,omitempty"`
 	Timed        *timed   `yaml:"timed,omitempty" json:"timed,omitempty"`
 	Complex      *complex `yaml:"complex,omitempty" json:"complex,omitempty"`
-
-	ColliderRect  *image.Rectangle
-	currentFrame  int
-	nextFrameTime int64
+	Mode         Mode     `yaml:"mode" json:"mode"`
+	LoopCount    int      `yaml:"loop_count" json:"loop_count"`
+	// Direction is set on the variants of a directional animation
+	Direction  Direction                  `yaml:"-" json:"direction,omitempty"`
+	Directions map[Direction]*directional `yaml:"directions,omitempty" json:"-"`
+	Variants   map[Direction]*Animation   `yaml:"-" json:"variants,omitempty"`
+	// Anchor is the point of the tiles drawn at the position of the animation, eg. the feet of a character
+	Anchor image.Point `yaml:"-" json:"anchor"`
+	// Mirrored is set on the variants mirroring another direction, their part offsets and anchor X are
+	// negated and their tiles extend left of their offsets, see PartPosition
+	Mirrored bool `yaml:"-" json:"mirrored,omitempty"`
+
+	// Colliders are named collision rects of the whole animation, eg. slices of an imported sprite sheet
+	Colliders map[string]image.Rectangle `yaml:"-" json:"colliders,omitempty"`
+
+	ColliderRect *image.Rectangle
 }
 
-func (a *Animation) GetTileID(part int) int {
-	frame := a.Frames[a.currentFrame]
-
-	if part < 0 || part >= len(frame.Parts) {
-		return -1
+// PartPosition returns the top left corner of a part drawn from a tile width pixels wide, relative to
+// the position of the animation.
+func (a *Animation) PartPosition(p Part, width int) image.Point {
+	pos := image.Pt(p.XOffset, p.YOffset).Sub(a.Anchor)
+	if a.Mirrored {
+		pos.X -= width
 	}
-	return frame.Parts[part].TileID
-}
-
-func (a *Animation) GetCurrentFrame() Frame {
-	return a.Frames[a.currentFrame]
-}
-
-func (a *Animation) GetNextFrame() Frame {
-	a.determineFrame()
-	return a.Frames[a.currentFrame]
-}
-
-func (a *Animation) determineFrame() {
-	if a.nextFrameTime == 0 {
-		a.currentFrame = 0
-		a.setNextFrameTime()
-		return
-	}
-	if time.Now().UnixMilli() >= a.nextFrameTime {
-		a.currentFrame++
-		if a.currentFrame >= len(a.Frames) {
-			a.currentFrame = 0
-		}
-		a.setNextFrameTime()
-	}
-}
-
-func (a *Animation) setNextFrameTime() {
-	frame := a.Frames[a.currentFrame]
-	a.nextFrameTime = time.Now().UnixMilli() + int64(frame.Duration)
+	return pos
 }
 
 func (a *Animation) decodeTilesetGroup(tilesetGroups []*TilesetGroup) {
@@ -98,93 +89,147 @@ func (a *Animation) decodeTilesetGroup(tilesetGroups []*TilesetGroup) {
 	a.TilesetGroup = ""
 }
 
-func (a *Animation) decodeSimple() {
+// decodeFrames builds the frames of the animation, frames and parts with problems are left out.
+// It returns ValidationErrors listing every problem, or nil.
+func (a *Animation) decodeFrames() error {
+	var errs ValidationErrors
+	errs = append(errs, a.decodeSimple()...)
+	errs = append(errs, a.decodeTimed()...)
+	errs = append(errs, a.decodeComplex()...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (a *Animation) decodeSimple() ValidationErrors {
 	if a.Simple == nil {
-		return
+		return nil
 	}
-	seq := func(yield func(Frame) bool) {
-		durationPerFrame := a.Simple.Duration / len(a.Simple.Frames)
-		for idx := range a.Simple.Frames {
-			part := Part{
-				TileID:         a.Simple.Frames[idx],
-				FlipHorizontal: a.Simple.Defaults.FlipHorizontal,
-				FlipVertical:   a.Simple.Defaults.FlipVertical,
-			}
-			frame := Frame{
-				Duration: durationPerFrame,
-				Parts:    []Part{part},
-			}
-			if !yield(frame) {
-				return
-			}
+	if len(a.Simple.Frames) == 0 {
+		return ValidationErrors{a.validationError(-1, -1, ErrNoFrames)}
+	}
+	a.decodeAnchor(a.Simple.Defaults)
+
+	var errs ValidationErrors
+	durationPerFrame := a.Simple.Duration / len(a.Simple.Frames)
+	for idx, frame := range a.Simple.Frames {
+		part, err := a.decodePart(idx, frame.ID, a.Simple.Defaults, frame.overrides)
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
+		a.Frames = append(a.Frames, Frame{
+			Duration: durationPerFrame,
+			Parts:    []Part{part},
+			Events:   a.Simple.Events[idx],
+		})
 	}
-	a.Frames = slices.AppendSeq(a.Frames, seq)
 	a.Simple = nil
+	return errs
 }
 
-func (a *Animation) decodeTimed() {
+func (a *Animation) decodeTimed() ValidationErrors {
 	if a.Timed == nil {
-		return
+		return nil
 	}
-	seq := func(yield func(Frame) bool) {
-		for _, frame := range a.Timed.Frames {
-			part := Part{
-				TileID:         frame.ID,
-				FlipHorizontal: a.Timed.Defaults.FlipHorizontal,
-				FlipVertical:   a.Timed.Defaults.FlipVertical,
-			}
-			f := Frame{
-				Duration: frame.Duration,
-				Parts:    []Part{part},
-			}
-			if !yield(f) {
-				return
-			}
+	a.decodeAnchor(a.Timed.Defaults)
+
+	var errs ValidationErrors
+	for idx, frame := range a.Timed.Frames {
+		part, err := a.decodePart(idx, frame.ID, a.Timed.Defaults, frame.overrides)
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
+		a.Frames = append(a.Frames, Frame{
+			Duration: frame.Duration,
+			Parts:    []Part{part},
+			Events:   frame.Events,
+		})
 	}
-	a.Frames = slices.AppendSeq(a.Frames, seq)
 	a.Timed = nil
+	return errs
 }
 
-func (a *Animation) decodeComplex() {
-	if a.Complex == nil {
-		return
+func (a *Animation) decodeAnchor(d defaults) {
+	if d.Anchor != nil {
+		a.Anchor = image.Pt(d.Anchor.X, d.Anchor.Y)
 	}
+}
 
-	frameSeq := func(yield func(Frame) bool) {
-		for _, frame := range a.Complex.Frames {
-
-			partSeq := func(yield func(Part) bool) {
-				for _, part := range frame.Parts {
-					p := Part{
-						TileID:         part.ID,
-						Tileset:        a.Tilesets[part.Tileset-1],
-						XOffset:        part.XOffset,
-						YOffset:        part.YOffset,
-						FlipHorizontal: part.FlipHorizontal,
-						FlipVertical:   part.FlipVertical,
-					}
-					if !yield(p) {
-						return
-					}
-				}
-			}
+// decodePart builds the part of a simple or timed frame, values set on the frame win over the defaults.
+func (a *Animation) decodePart(frame int, id int, d defaults, o overrides) (Part, *ValidationError) {
+	p := Part{
+		Layer:          d.Layer,
+		TileID:         id,
+		XOffset:        d.XOffset,
+		YOffset:        d.YOffset,
+		FlipHorizontal: d.FlipHorizontal,
+		FlipVertical:   d.FlipVertical,
+	}
+	tileset := d.Tileset
 
-			parts := slices.AppendSeq([]Part{}, partSeq)
+	if o.XOffset != nil {
+		p.XOffset = *o.XOffset
+	}
+	if o.YOffset != nil {
+		p.YOffset = *o.YOffset
+	}
+	if o.FlipHorizontal != nil {
+		p.FlipHorizontal = *o.FlipHorizontal
+	}
+	if o.FlipVertical != nil {
+		p.FlipVertical = *o.FlipVertical
+	}
+	if o.Tileset != nil {
+		tileset = *o.Tileset
+	}
 
-			f := Frame{
-				Duration: frame.Duration,
-				Parts:    parts,
-			}
-			if !yield(f) {
-				return
+	// Without a tileset index the tile is drawn from every tileset
+	if tileset != 0 {
+		if tileset < 1 || tileset > len(a.Tilesets) {
+			return Part{}, a.validationError(frame, 0, fmt.Errorf("tileset:%d %w", tileset, ErrInvalidTilesetIndex))
+		}
+		p.Tileset = a.Tilesets[tileset-1]
+	}
+	return p, nil
+}
+
+func (a *Animation) decodeComplex() ValidationErrors {
+	if a.Complex == nil {
+		return nil
+	}
+
+	var errs ValidationErrors
+	for i, frame := range a.Complex.Frames {
+		parts := make([]Part, 0, len(frame.Parts))
+		for j, part := range frame.Parts {
+			// Tileset indexes are 1 based
+			if part.Tileset < 1 || part.Tileset > len(a.Tilesets) {
+				errs = append(errs, a.validationError(i, j, fmt.Errorf("tileset:%d %w", part.Tileset, ErrInvalidTilesetIndex)))
+				continue
 			}
+			parts = append(parts, Part{
+				Layer:          part.Layer,
+				TileID:         part.ID,
+				Tileset:        a.Tilesets[part.Tileset-1],
+				XOffset:        part.XOffset,
+				YOffset:        part.YOffset,
+				FlipHorizontal: part.FlipHorizontal,
+				FlipVertical:   part.FlipVertical,
+			})
 		}
+
+		a.Frames = append(a.Frames, Frame{
+			Duration: frame.Duration,
+			Parts:    parts,
+			Events:   frame.Events,
+		})
 	}
 
-	a.Frames = slices.AppendSeq(a.Frames, frameSeq)
 	a.Complex = nil
+	return errs
 }
 
 type TilesetGroup struct {
@@ -214,13 +259,25 @@ func fileReader(source string, r io.Reader) (*Animations, error) {
 		return nil, err
 	}
 
+	// Every problem of the file is reported at once
+	var errs ValidationErrors
 	for _, animation := range animations.Animations {
+		animation.Source = source
 		animation.Frames = []Frame{}
 
+		if err := animation.decodeMode(); err != nil {
+			errs = append(errs, animation.validationError(-1, -1, err))
+		}
 		animation.decodeTilesetGroup(animations.TilesetGroups)
-		animation.decodeSimple()
-		animation.decodeTimed()
-		animation.decodeComplex()
+		if err := animation.decodeFrames(); err != nil {
+			errs = append(errs, err.(ValidationErrors)...)
+		}
+		if err := animation.decodeDirections(); err != nil {
+			errs = append(errs, err.(ValidationErrors)...)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
 	return animations, nil
@@ -237,34 +294,72 @@ func LoadFile(fileName string) (*Animations, error) {
 	return fileReader(fileName, f)
 }
 
+type point struct {
+	X int `yaml:"x"`
+	Y int `yaml:"y"`
+}
+
 type defaults struct {
 	FlipHorizontal bool `yaml:"flip_horizontal"`
 	FlipVertical   bool `yaml:"flip_vertical"`
 	XOffset        int  `yaml:"x_offset"`
 	YOffset        int  `yaml:"y_offset"`
+	// Tileset is the 1 based index of the tileset to draw from, 0 draws from every tileset
+	Tileset int    `yaml:"tileset"`
+	Anchor  *point `yaml:"anchor"`
+	Layer   string `yaml:"layer"`
+}
+
+// overrides are set on a single frame and replace the defaults
+type overrides struct {
+	FlipHorizontal *bool `yaml:"flip_horizontal"`
+	FlipVertical   *bool `yaml:"flip_vertical"`
+	XOffset        *int  `yaml:"x_offset"`
+	YOffset        *int  `yaml:"y_offset"`
+	Tileset        *int  `yaml:"tileset"`
 }
+
+// simpleFrame is either a tile id or a mapping with the id and overrides
+type simpleFrame struct {
+	ID        int `yaml:"id"`
+	overrides `yaml:",inline"`
+}
+
+func (f *simpleFrame) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&f.ID)
+	}
+	type plain simpleFrame
+	return value.Decode((*plain)(f))
+}
+
 type simple struct {
-	Duration int      `yaml:"duration"`
-	Defaults defaults `yaml:"defaults"`
-	Frames   []int    `yaml:"frames"`
+	Duration int             `yaml:"duration"`
+	Defaults defaults        `yaml:"defaults"`
+	Frames   []simpleFrame   `yaml:"frames"`
+	Events   map[int][]Event `yaml:"events"`
 }
 type timed struct {
 	Defaults defaults `yaml:"defaults"`
 	Frames   []struct {
-		ID       int `yaml:"id"`
-		Duration int `yaml:"duration"`
+		ID        int     `yaml:"id"`
+		Duration  int     `yaml:"duration"`
+		Events    []Event `yaml:"events"`
+		overrides `yaml:",inline"`
 	} `yaml:"frames"`
 }
 type complex struct {
 	Frames []struct {
-		Duration int `yaml:"duration"`
+		Duration int     `yaml:"duration"`
+		Events   []Event `yaml:"events"`
 		Parts    []struct {
-			ID             int  `yaml:"id"`
-			Tileset        int  `yaml:"tileset"`
-			XOffset        int  `yaml:"x_offset"`
-			YOffset        int  `yaml:"y_offset"`
-			FlipHorizontal bool `yaml:"flip_horizontal"`
-			FlipVertical   bool `yaml:"flip_vertical"`
+			ID             int    `yaml:"id"`
+			Layer          string `yaml:"layer"`
+			Tileset        int    `yaml:"tileset"`
+			XOffset        int    `yaml:"x_offset"`
+			YOffset        int    `yaml:"y_offset"`
+			FlipHorizontal bool   `yaml:"flip_horizontal"`
+			FlipVertical   bool   `yaml:"flip_vertical"`
 		} `yaml:"parts"`
 	} `yaml:"frames"`
 }
@@ -0,0 +1,328 @@
+package aseprite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/talvor/tiled/animation"
+	"github.com/talvor/tiled/tsx"
+)
+
+var (
+	ErrNotAseprite = errors.New("aseprite: not an aseprite sprite sheet")
+	ErrNoFrames    = errors.New("aseprite: sprite sheet has no frames")
+	ErrNotGrid     = errors.New("aseprite: frames are not laid out on a grid")
+	ErrTrimmed     = errors.New("aseprite: trimmed frames are not supported, export without trim")
+)
+
+// DefaultAction is the action used for sprite sheets without frame tags.
+const DefaultAction = "default"
+
+type Rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type Size struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type Frame struct {
+	Filename         string `json:"filename"`
+	Frame            Rect   `json:"frame"`
+	Rotated          bool   `json:"rotated"`
+	Trimmed          bool   `json:"trimmed"`
+	SpriteSourceSize Rect   `json:"spriteSourceSize"`
+	SourceSize       Size   `json:"sourceSize"`
+	Duration         int    `json:"duration"`
+}
+
+type FrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+	Repeat    string `json:"repeat"`
+}
+
+type SliceKey struct {
+	Frame  int  `json:"frame"`
+	Bounds Rect `json:"bounds"`
+}
+
+type Slice struct {
+	Name string     `json:"name"`
+	Keys []SliceKey `json:"keys"`
+}
+
+type Meta struct {
+	App       string     `json:"app"`
+	Image     string     `json:"image"`
+	Size      Size       `json:"size"`
+	FrameTags []FrameTag `json:"frameTags"`
+	Slices    []Slice    `json:"slices"`
+}
+
+// Sheet is a sprite sheet exported by Aseprite with JSON data, in either the hash or the array layout.
+type Sheet struct {
+	Source string
+	Frames []Frame
+	Meta   Meta
+}
+
+func (s *Sheet) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Frames json.RawMessage `json:"frames"`
+		Meta   Meta            `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	s.Meta = raw.Meta
+
+	frames := bytes.TrimSpace(raw.Frames)
+	if len(frames) > 0 && frames[0] == '[' {
+		return json.Unmarshal(frames, &s.Frames)
+	}
+	return s.decodeFrameHash(frames)
+}
+
+// decodeFrameHash decodes the hash layout keeping the order of the frames, which is their index.
+func (s *Sheet) decodeFrameHash(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		var f Frame
+		if err := d.Decode(&f); err != nil {
+			return err
+		}
+		f.Filename, _ = t.(string)
+		s.Frames = append(s.Frames, f)
+	}
+	return nil
+}
+
+// LoadFile loads an Aseprite JSON file. Files that are not Aseprite sprite sheets return ErrNotAseprite.
+func LoadFile(fileName string) (*Sheet, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sheet{Source: fileName}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("file: %s %w", fileName, ErrNotAseprite)
+	}
+	if !strings.Contains(s.Meta.App, "aseprite") {
+		return nil, fmt.Errorf("file: %s %w", fileName, ErrNotAseprite)
+	}
+	return s, nil
+}
+
+// Options control how a sheet is converted.
+type Options struct {
+	// Class of the animations, defaults to the file name without extensions
+	Class string
+	// TilesetName of the generated tileset, defaults to the class
+	TilesetName string
+}
+
+// Import converts the sheet into animations, one per frame tag, drawn from a tileset generated for
+// the sheet image. Slices become the colliders of the animations.
+func (s *Sheet) Import(opts Options) (*animation.Animations, *tsx.Tileset, error) {
+	if len(s.Frames) == 0 {
+		return nil, nil, fmt.Errorf("file: %s %w", s.Source, ErrNoFrames)
+	}
+
+	class := opts.Class
+	if class == "" {
+		class = strings.Split(filepath.Base(s.Source), ".")[0]
+	}
+	tilesetName := opts.TilesetName
+	if tilesetName == "" {
+		tilesetName = class
+	}
+
+	ts, tileIDs, err := s.tileset(tilesetName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags := s.Meta.FrameTags
+	if len(tags) == 0 {
+		tags = []FrameTag{{Name: DefaultAction, From: 0, To: len(s.Frames) - 1}}
+	}
+
+	anis := &animation.Animations{Source: s.Source}
+	for _, tag := range tags {
+		ani := &animation.Animation{
+			Source:    s.Source,
+			Class:     class,
+			Action:    tag.Name,
+			Tilesets:  []string{tilesetName},
+			Mode:      animation.ModeLoop,
+			Colliders: s.colliders(tag),
+		}
+
+		var indices []int
+		for i := tag.From; i <= tag.To && i < len(s.Frames); i++ {
+			indices = append(indices, i)
+		}
+
+		switch tag.Direction {
+		case "reverse":
+			slices.Reverse(indices)
+		case "pingpong":
+			ani.Mode = animation.ModePingPong
+		case "pingpong_reverse":
+			slices.Reverse(indices)
+			ani.Mode = animation.ModePingPong
+		}
+		if repeat, err := strconv.Atoi(tag.Repeat); err == nil && repeat > 0 {
+			ani.LoopCount = repeat
+		}
+
+		for _, i := range indices {
+			ani.Frames = append(ani.Frames, animation.Frame{
+				Duration: s.Frames[i].Duration,
+				Parts:    []animation.Part{{TileID: tileIDs[i]}},
+			})
+		}
+		anis.Animations = append(anis.Animations, ani)
+	}
+
+	return anis, ts, nil
+}
+
+// tileset describes the sheet image as a tileset and returns the tile id of every frame. The margin and
+// spacing of the tileset are worked out from the positions of the frames, so sheets exported with border,
+// shape or inner padding keep their layout.
+func (s *Sheet) tileset(name string) (*tsx.Tileset, []int, error) {
+	tileWidth, tileHeight := s.Frames[0].Frame.W, s.Frames[0].Frame.H
+	if tileWidth == 0 || tileHeight == 0 {
+		return nil, nil, fmt.Errorf("file: %s %w", s.Source, ErrNotGrid)
+	}
+
+	var xs, ys []int
+	for i, f := range s.Frames {
+		if f.Trimmed {
+			return nil, nil, fmt.Errorf("file: %s frame: %d %w", s.Source, i, ErrTrimmed)
+		}
+		r := f.Frame
+		if f.Rotated || r.W != tileWidth || r.H != tileHeight {
+			return nil, nil, fmt.Errorf("file: %s frame: %d %w", s.Source, i, ErrNotGrid)
+		}
+		xs = append(xs, r.X)
+		ys = append(ys, r.Y)
+	}
+
+	marginX, spacingX, okX := gridAxis(xs, tileWidth)
+	marginY, spacingY, okY := gridAxis(ys, tileHeight)
+	if !okX || !okY || marginX != marginY {
+		return nil, nil, fmt.Errorf("file: %s %w", s.Source, ErrNotGrid)
+	}
+	// A single row or column leaves the spacing of its axis to the other one
+	spacing := max(spacingX, spacingY, 0)
+	if spacingX >= 0 && spacingY >= 0 && spacingX != spacingY {
+		return nil, nil, fmt.Errorf("file: %s %w", s.Source, ErrNotGrid)
+	}
+	margin := marginX
+
+	columns := (s.Meta.Size.W - margin + spacing) / (tileWidth + spacing)
+	rows := (s.Meta.Size.H - margin + spacing) / (tileHeight + spacing)
+
+	tileIDs := make([]int, len(s.Frames))
+	for i, f := range s.Frames {
+		column := (f.Frame.X - margin) / (tileWidth + spacing)
+		row := (f.Frame.Y - margin) / (tileHeight + spacing)
+		if column >= columns || row >= rows {
+			return nil, nil, fmt.Errorf("file: %s frame: %d %w", s.Source, i, ErrNotGrid)
+		}
+		tileIDs[i] = row*columns + column
+	}
+
+	ts := &tsx.Tileset{
+		Source:     s.Source,
+		Name:       name,
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+		Margin:     margin,
+		Spacing:    spacing,
+		TileCount:  columns * rows,
+		Columns:    columns,
+		Image: tsx.Image{
+			Source: path.Join(filepath.Dir(s.Source), s.Meta.Image),
+			Width:  s.Meta.Size.W,
+			Height: s.Meta.Size.H,
+		},
+	}
+	return ts, tileIDs, nil
+}
+
+// gridAxis returns the margin and spacing of frames of the given size at the positions along an axis.
+// The spacing is -1 when every frame is at the same position, ok is false when they are not on a grid.
+func gridAxis(positions []int, size int) (margin int, spacing int, ok bool) {
+	positions = slices.Compact(slices.Sorted(slices.Values(positions)))
+	margin = positions[0]
+	if len(positions) == 1 {
+		return margin, -1, margin >= 0
+	}
+
+	step := slices.Min(diffs(positions))
+	for _, p := range positions {
+		if (p-margin)%step != 0 {
+			return 0, 0, false
+		}
+	}
+	return margin, step - size, margin >= 0 && step >= size
+}
+
+func diffs(positions []int) []int {
+	d := make([]int, len(positions)-1)
+	for i := range d {
+		d[i] = positions[i+1] - positions[i]
+	}
+	return d
+}
+
+// colliders returns the union of the bounds of each slice over the frames of the tag.
+// Slice keys apply from their frame until the next key.
+func (s *Sheet) colliders(tag FrameTag) map[string]image.Rectangle {
+	colliders := make(map[string]image.Rectangle)
+	for _, slice := range s.Meta.Slices {
+		var rect image.Rectangle
+		for i, key := range slice.Keys {
+			end := len(s.Frames)
+			if i+1 < len(slice.Keys) {
+				end = slice.Keys[i+1].Frame
+			}
+			if key.Frame > tag.To || end <= tag.From {
+				continue
+			}
+			b := key.Bounds
+			rect = rect.Union(image.Rect(b.X, b.Y, b.X+b.W, b.Y+b.H))
+		}
+		if !rect.Empty() {
+			colliders[slice.Name] = rect
+		}
+	}
+	return colliders
+}
@@ -0,0 +1,202 @@
+package aseprite
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/talvor/tiled/animation"
+)
+
+// sheetJSON returns an Aseprite sheet in the array layout with 16x16 frames at the positions.
+func sheetJSON(width, height int, positions []image.Point, meta string) string {
+	var frames []string
+	for i, p := range positions {
+		frames = append(frames, fmt.Sprintf(
+			`{"filename": "hero %d.aseprite", "frame": {"x": %d, "y": %d, "w": 16, "h": 16}, "rotated": false, "trimmed": false, "spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 100}`,
+			i, p.X, p.Y))
+	}
+	return fmt.Sprintf(`{"frames": [%s], "meta": {"app": "https://www.aseprite.org/", "image": "hero.png", "size": {"w": %d, "h": %d}%s}}`,
+		strings.Join(frames, ","), width, height, meta)
+}
+
+func loadSheet(t *testing.T, data string) (*Sheet, error) {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), "hero.json")
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadFile(fileName)
+}
+
+func TestImportPlainSheet(t *testing.T) {
+	s, err := loadSheet(t, `{
+  "frames": {
+    "hero 0.aseprite": {"frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 100},
+    "hero 1.aseprite": {"frame": {"x": 16, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 150},
+    "hero 2.aseprite": {"frame": {"x": 0, "y": 16, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 200}
+  },
+  "meta": {"app": "https://www.aseprite.org/", "image": "hero.png", "size": {"w": 32, "h": 32}}
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anis, ts, err := s.Import(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Name != "hero" || ts.Columns != 2 || ts.TileCount != 4 || ts.Margin != 0 || ts.Spacing != 0 {
+		t.Errorf("tileset: %+v", ts)
+	}
+	if len(anis.Animations) != 1 {
+		t.Fatalf("got %d animations, want 1", len(anis.Animations))
+	}
+
+	a := anis.Animations[0]
+	if a.Class != "hero" || a.Action != DefaultAction {
+		t.Errorf("got %s %s, want hero %s", a.Class, a.Action, DefaultAction)
+	}
+	var tiles, durations []int
+	for _, f := range a.Frames {
+		tiles = append(tiles, f.Parts[0].TileID)
+		durations = append(durations, f.Duration)
+	}
+	if !slices.Equal(tiles, []int{0, 1, 2}) || !slices.Equal(durations, []int{100, 150, 200}) {
+		t.Errorf("got tiles %v durations %v", tiles, durations)
+	}
+}
+
+func TestImportPaddedSheet(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		positions     []image.Point
+		margin        int
+		spacing       int
+		columns       int
+	}{
+		{
+			name:  "border and shape padding",
+			width: 37, height: 37,
+			positions: []image.Point{{2, 2}, {19, 2}, {2, 19}, {19, 19}},
+			margin:    2, spacing: 1, columns: 2,
+		},
+		{
+			name:  "single row",
+			width: 56, height: 18,
+			positions: []image.Point{{1, 1}, {19, 1}, {37, 1}},
+			margin:    1, spacing: 2, columns: 3,
+		},
+		{
+			name:  "inner padding",
+			width: 40, height: 20,
+			positions: []image.Point{{2, 2}, {22, 2}},
+			margin:    2, spacing: 4, columns: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := loadSheet(t, sheetJSON(tt.width, tt.height, tt.positions, ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			anis, ts, err := s.Import(Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ts.Margin != tt.margin || ts.Spacing != tt.spacing || ts.Columns != tt.columns {
+				t.Errorf("got margin %d spacing %d columns %d, want %d %d %d", ts.Margin, ts.Spacing, ts.Columns, tt.margin, tt.spacing, tt.columns)
+			}
+
+			for i, f := range anis.Animations[0].Frames {
+				rect, err := ts.GetTileRect(uint32(f.Parts[0].TileID))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := image.Rectangle{Min: tt.positions[i], Max: tt.positions[i].Add(image.Pt(16, 16))}
+				if rect != want {
+					t.Errorf("frame %d: tile rect %v, want %v", i, rect, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportTags(t *testing.T) {
+	tags := `, "frameTags": [
+  {"name": "walk", "from": 0, "to": 3, "direction": "forward", "repeat": "2"},
+  {"name": "back", "from": 0, "to": 2, "direction": "reverse"},
+  {"name": "bounce", "from": 1, "to": 3, "direction": "pingpong"},
+  {"name": "bounce_back", "from": 2, "to": 3, "direction": "pingpong_reverse"}
+], "slices": [
+  {"name": "hit", "keys": [{"frame": 0, "bounds": {"x": 2, "y": 2, "w": 4, "h": 4}}, {"frame": 2, "bounds": {"x": 8, "y": 8, "w": 4, "h": 4}}]}
+]`
+	s, err := loadSheet(t, sheetJSON(64, 16, []image.Point{{0, 0}, {16, 0}, {32, 0}, {48, 0}}, tags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	anis, _, err := s.Import(Options{Class: "knight"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		action   string
+		tiles    []int
+		mode     animation.Mode
+		loops    int
+		collider image.Rectangle
+	}{
+		{"walk", []int{0, 1, 2, 3}, animation.ModeLoop, 2, image.Rect(2, 2, 12, 12)},
+		{"back", []int{2, 1, 0}, animation.ModeLoop, 0, image.Rect(2, 2, 12, 12)},
+		{"bounce", []int{1, 2, 3}, animation.ModePingPong, 0, image.Rect(2, 2, 12, 12)},
+		{"bounce_back", []int{3, 2}, animation.ModePingPong, 0, image.Rect(8, 8, 12, 12)},
+	}
+	if len(anis.Animations) != len(tests) {
+		t.Fatalf("got %d animations, want %d", len(anis.Animations), len(tests))
+	}
+	for i, tt := range tests {
+		a := anis.Animations[i]
+		var tiles []int
+		for _, f := range a.Frames {
+			tiles = append(tiles, f.Parts[0].TileID)
+		}
+		if a.Class != "knight" || a.Action != tt.action || !slices.Equal(tiles, tt.tiles) || a.Mode != tt.mode || a.LoopCount != tt.loops {
+			t.Errorf("%s: got %s %s tiles %v mode %v loops %d", tt.action, a.Class, a.Action, tiles, a.Mode, a.LoopCount)
+		}
+		if got := a.Colliders["hit"]; got != tt.collider {
+			t.Errorf("%s: collider %v, want %v", tt.action, got, tt.collider)
+		}
+	}
+}
+
+func TestImportMalformed(t *testing.T) {
+	trimmed := strings.Replace(sheetJSON(32, 16, []image.Point{{0, 0}, {16, 0}}, ""), `"trimmed": false`, `"trimmed": true`, 1)
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"not json", `{"frames": [`, ErrNotAseprite},
+		{"other app", `{"frames": [], "meta": {"app": "texturepacker"}}`, ErrNotAseprite},
+		{"no frames", `{"frames": [], "meta": {"app": "https://www.aseprite.org/"}}`, ErrNoFrames},
+		{"trimmed", trimmed, ErrTrimmed},
+		{"off the grid", sheetJSON(64, 16, []image.Point{{0, 0}, {16, 0}, {40, 0}}, ""), ErrNotGrid},
+		{"different margins", sheetJSON(34, 34, []image.Point{{2, 0}, {18, 16}}, ""), ErrNotGrid},
+	}
+	for _, tt := range tests {
+		s, err := loadSheet(t, tt.data)
+		if err == nil {
+			_, _, err = s.Import(Options{})
+		}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package animation
+
+import (
+	"github.com/talvor/tiled/clock"
+)
+
+// AnimationResolver finds the animation for a class and action, eg. manager.AnimationManager.
+type AnimationResolver interface {
+	GetAnimation(class string, action string) (*Animation, error)
+}
+
+// StateController runs a StateMachine for a single entity. Game code sets parameters and calls
+// Update, the controller switches its player to the animation of the current state.
+type StateController struct {
+	Machine *StateMachine
+	Player  *AnimationPlayer
+
+	resolver AnimationResolver
+	state    *State
+	// animation is the animation of the state, the player plays its variant for direction
+	animation *Animation
+	direction Direction
+	params    map[string]any
+	triggers  map[string]bool
+	onChange  []func(from string, to string)
+}
+
+// NewStateController returns a controller in the initial state of the machine. A nil clock plays the
+// animations with clock.Default.
+func NewStateController(sm *StateMachine, resolver AnimationResolver, c clock.Clock) (*StateController, error) {
+	sc := &StateController{
+		Machine:  sm,
+		resolver: resolver,
+		params:   make(map[string]any),
+		triggers: make(map[string]bool),
+	}
+	for name, value := range sm.Parameters {
+		sc.params[name] = value
+	}
+
+	initial, err := sm.GetState(sm.Initial)
+	if err != nil {
+		return nil, err
+	}
+	ani, err := resolver.GetAnimation(sm.Class, initial.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.state = initial
+	sc.animation = ani
+	sc.Player = NewAnimationPlayer(ani.Variant(sc.direction))
+	if c != nil {
+		sc.Player.Clock = c
+	}
+	return sc, nil
+}
+
+// State returns the name of the current state.
+func (sc *StateController) State() string {
+	return sc.state.Name
+}
+
+// Direction returns the facing set with SetDirection, empty until it is set.
+func (sc *StateController) Direction() Direction {
+	return sc.direction
+}
+
+// SetDirection switches the player to the variant of the current animation for the direction without
+// restarting it. The facing is kept when the state changes. An empty direction plays the default variant.
+func (sc *StateController) SetDirection(dir Direction) {
+	sc.direction = dir
+	sc.Player.SetAnimationKeepPhase(sc.animation.Variant(dir))
+}
+
+// OnStateChange registers a callback called after every transition.
+func (sc *StateController) OnStateChange(callback func(from string, to string)) {
+	sc.onChange = append(sc.onChange, callback)
+}
+
+func (sc *StateController) Set(name string, value any) {
+	sc.params[name] = value
+}
+
+func (sc *StateController) SetBool(name string, value bool) {
+	sc.params[name] = value
+}
+
+func (sc *StateController) SetFloat(name string, value float64) {
+	sc.params[name] = value
+}
+
+// SetTrigger sets a parameter that is reset once a transition using it has fired.
+func (sc *StateController) SetTrigger(name string) {
+	sc.params[name] = true
+	sc.triggers[name] = true
+}
+
+func (sc *StateController) Get(name string) any {
+	return sc.params[name]
+}
+
+// Update advances the player and fires the first transition whose rules allow it.
+func (sc *StateController) Update() error {
+	sc.Player.Update()
+
+	for _, t := range sc.Machine.Transitions {
+		if !sc.canTransition(t) {
+			continue
+		}
+		if err := sc.transition(t); err != nil {
+			return err
+		}
+		break
+	}
+	return nil
+}
+
+// ForceState switches to a state ignoring transitions and interrupt rules.
+func (sc *StateController) ForceState(name string) error {
+	return sc.transition(&Transition{From: sc.state.Name, To: name})
+}
+
+func (sc *StateController) canTransition(t *Transition) bool {
+	if t.From != AnyState && t.From != sc.state.Name {
+		return false
+	}
+	if t.To == sc.state.Name && t.From == AnyState {
+		return false
+	}
+
+	done := sc.Player.Finished() || sc.Player.Loops() > 0
+	if t.Wait && !done {
+		return false
+	}
+	if !sc.state.interruptible() && !t.Interrupt && !done {
+		return false
+	}
+
+	for _, c := range t.conditions {
+		if !c.eval(sc.params) {
+			return false
+		}
+	}
+	return true
+}
+
+func (sc *StateController) transition(t *Transition) error {
+	to, err := sc.Machine.GetState(t.To)
+	if err != nil {
+		return err
+	}
+	ani, err := sc.resolver.GetAnimation(sc.Machine.Class, to.Action)
+	if err != nil {
+		return err
+	}
+
+	progress := sc.Player.Progress()
+	sc.animation = ani
+	sc.Player.Animation = ani.Variant(sc.direction)
+	sc.Player.Reset()
+	if t.Sync {
+		sc.Player.Seek(progress)
+	}
+
+	for _, c := range t.conditions {
+		if sc.triggers[c.param] {
+			delete(sc.triggers, c.param)
+			sc.params[c.param] = false
+		}
+	}
+
+	from := sc.state.Name
+	sc.state = to
+	for _, callback := range sc.onChange {
+		callback(from, to.Name)
+	}
+	return nil
+}
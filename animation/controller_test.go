@@ -0,0 +1,130 @@
+package animation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newController(t *testing.T, a *Animation) *StateController {
+	t.Helper()
+	sm := &StateMachine{
+		Class:   "hero",
+		Initial: "walk",
+		States:  []*State{{Name: "walk", Action: "walk"}},
+	}
+	if err := sm.decode(); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewStateController(sm, resolverFunc(func(string, string) (*Animation, error) { return a, nil }), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sc
+}
+
+func TestStateControllerNilClock(t *testing.T) {
+	sc := newController(t, &Animation{Frames: frames(2)})
+	if sc.Player.Clock == nil {
+		t.Fatal("player of a controller built without a clock has no clock")
+	}
+	if err := sc.Update(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const walkAndIdle = `
+animations:
+  - class: hero
+    action: walk
+    tilesets:
+      - hero
+    directions:
+      down:
+        simple:
+          duration: 400
+          frames: [1, 2, 3, 4]
+      right:
+        simple:
+          duration: 400
+          frames: [5, 6, 7, 8]
+      left:
+        mirror: right
+  - class: hero
+    action: idle
+    tilesets:
+      - hero
+    directions:
+      down:
+        simple:
+          duration: 200
+          frames: [10, 11]
+      right:
+        simple:
+          duration: 200
+          frames: [12, 13]
+`
+
+func TestStateControllerKeepsDirection(t *testing.T) {
+	animations, err := fileReader("test.ani", strings.NewReader(walkAndIdle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byAction := map[string]*Animation{}
+	for _, a := range animations.Animations {
+		byAction[a.Action] = a
+	}
+
+	sm := &StateMachine{
+		Class:   "hero",
+		Initial: "walk",
+		States:  []*State{{Name: "walk", Action: "walk"}, {Name: "idle", Action: "idle"}},
+	}
+	if err := sm.decode(); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := NewStateController(sm, resolverFunc(func(_ string, action string) (*Animation, error) {
+		return byAction[action], nil
+	}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.Player.Animation.Direction; got != DirectionDown {
+		t.Fatalf("initial variant faces %q, want down", got)
+	}
+
+	sc.SetDirection(DirectionRight)
+	sc.Player.Advance(0)
+	sc.Player.Advance(250 * time.Millisecond)
+	if got := sc.Player.GetCurrentFrame().Parts[0].TileID; got != 7 {
+		t.Fatalf("facing right: tile %d, want 7", got)
+	}
+
+	// Turning keeps the phase of the walk cycle
+	sc.SetDirection(DirectionLeft)
+	if sc.Player.Animation.Direction != DirectionLeft || !sc.Player.Animation.Mirrored {
+		t.Fatalf("facing left: variant %q mirrored %v", sc.Player.Animation.Direction, sc.Player.Animation.Mirrored)
+	}
+	if got := sc.Player.GetCurrentFrame().Parts[0].TileID; got != 7 {
+		t.Errorf("turned left: tile %d, want 7", got)
+	}
+
+	// Idle has no left variant and falls back to facing down, the controller still faces left
+	if err := sc.ForceState("idle"); err != nil {
+		t.Fatal(err)
+	}
+	if sc.Direction() != DirectionLeft {
+		t.Errorf("direction after the transition: %q, want left", sc.Direction())
+	}
+	if sc.Player.Animation != byAction["idle"].Variant(DirectionLeft) {
+		t.Errorf("idle plays the %q variant, want the variant for left", sc.Player.Animation.Direction)
+	}
+
+	if err := sc.ForceState("walk"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.Player.Animation.Direction; got != DirectionLeft {
+		t.Errorf("walk after idle faces %q, want left", got)
+	}
+}
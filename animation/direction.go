@@ -0,0 +1,194 @@
+package animation
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"maps"
+	"math"
+	"slices"
+)
+
+var ErrUnknownDirection = errors.New("animation: unknown direction")
+
+// Direction is the facing of a directional animation.
+type Direction string
+
+const (
+	DirectionUp        Direction = "up"
+	DirectionDown      Direction = "down"
+	DirectionLeft      Direction = "left"
+	DirectionRight     Direction = "right"
+	DirectionUpLeft    Direction = "up_left"
+	DirectionUpRight   Direction = "up_right"
+	DirectionDownLeft  Direction = "down_left"
+	DirectionDownRight Direction = "down_right"
+)
+
+// Directions in clockwise order starting from right, matching the angle of a facing vector in screen coordinates.
+var directions = []Direction{
+	DirectionRight,
+	DirectionDownRight,
+	DirectionDown,
+	DirectionDownLeft,
+	DirectionLeft,
+	DirectionUpLeft,
+	DirectionUp,
+	DirectionUpRight,
+}
+
+// DirectionFromVector returns the direction closest to the facing vector (dx, dy), with y pointing down.
+// When eight is false only up, down, left and right are returned.
+// A zero vector returns DirectionDown.
+func DirectionFromVector(dx, dy float64, eight bool) Direction {
+	if dx == 0 && dy == 0 {
+		return DirectionDown
+	}
+
+	angle := math.Atan2(dy, dx)
+	if eight {
+		sector := int(math.Round(angle/(math.Pi/4))+8) % 8
+		return directions[sector]
+	}
+	sector := int(math.Round(angle/(math.Pi/2))+4) % 4
+	return directions[sector*2]
+}
+
+// directional is a direction variant in an animation file.
+type directional struct {
+	Simple  *simple   `yaml:"simple,omitempty"`
+	Timed   *timed    `yaml:"timed,omitempty"`
+	Complex *complex  `yaml:"complex,omitempty"`
+	Mirror  Direction `yaml:"mirror,omitempty"`
+}
+
+// Variant returns the animation for the direction. Diagonal directions fall back to their horizontal
+// then vertical component. Without a matching variant the animation itself is returned, or when it
+// has no frames of its own its first variant, starting with down.
+func (a *Animation) Variant(dir Direction) *Animation {
+	if v, ok := a.Variants[dir]; ok {
+		return v
+	}
+
+	var fallbacks []Direction
+	switch dir {
+	case DirectionUpLeft:
+		fallbacks = []Direction{DirectionLeft, DirectionUp}
+	case DirectionUpRight:
+		fallbacks = []Direction{DirectionRight, DirectionUp}
+	case DirectionDownLeft:
+		fallbacks = []Direction{DirectionLeft, DirectionDown}
+	case DirectionDownRight:
+		fallbacks = []Direction{DirectionRight, DirectionDown}
+	}
+	if len(a.Frames) == 0 {
+		fallbacks = append(fallbacks, DirectionDown)
+		fallbacks = append(fallbacks, directions...)
+	}
+	for _, f := range fallbacks {
+		if v, ok := a.Variants[f]; ok {
+			return v
+		}
+	}
+	return a
+}
+
+// DefaultVariant returns the animation played when no direction is given: the animation itself when it
+// has frames, otherwise its variant facing down or the first variant it has.
+func (a *Animation) DefaultVariant() *Animation {
+	if a == nil || len(a.Frames) > 0 {
+		return a
+	}
+	return a.Variant(DirectionDown)
+}
+
+// decodeDirections builds the direction variants. It returns ValidationErrors listing every problem, or nil.
+func (a *Animation) decodeDirections() error {
+	if len(a.Directions) == 0 {
+		return nil
+	}
+	a.Variants = make(map[Direction]*Animation, len(a.Directions))
+
+	var errs ValidationErrors
+	var mirrors []Direction
+	for _, dir := range slices.Sorted(maps.Keys(a.Directions)) {
+		d := a.Directions[dir]
+		if !slices.Contains(directions, dir) {
+			errs = append(errs, a.validationError(-1, -1, fmt.Errorf("direction:%s %w", dir, ErrUnknownDirection)))
+			continue
+		}
+		if d.Mirror != "" {
+			mirrors = append(mirrors, dir)
+			continue
+		}
+
+		v := a.newVariant(dir)
+		v.Simple = d.Simple
+		v.Timed = d.Timed
+		v.Complex = d.Complex
+		if err := v.decodeFrames(); err != nil {
+			errs = append(errs, err.(ValidationErrors)...)
+		}
+		a.Variants[dir] = v
+	}
+
+	// Mirrors may reference other mirrors, resolve them until no progress is made
+	for len(mirrors) > 0 {
+		var pending []Direction
+		for _, dir := range mirrors {
+			source, ok := a.Variants[a.Directions[dir].Mirror]
+			if !ok {
+				pending = append(pending, dir)
+				continue
+			}
+			v := a.newVariant(dir)
+			v.Frames = mirrorFrames(source.Frames)
+			v.Anchor = image.Pt(-source.Anchor.X, source.Anchor.Y)
+			v.Mirrored = !source.Mirrored
+			a.Variants[dir] = v
+		}
+		if len(pending) == len(mirrors) {
+			for _, dir := range pending {
+				v := a.newVariant(dir)
+				errs = append(errs, v.validationError(-1, -1, fmt.Errorf("mirror:%s %w", a.Directions[dir].Mirror, ErrUnknownDirection)))
+			}
+			break
+		}
+		mirrors = pending
+	}
+
+	a.Directions = nil
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (a *Animation) newVariant(dir Direction) *Animation {
+	return &Animation{
+		Source:    a.Source,
+		Class:     a.Class,
+		Action:    a.Action,
+		Direction: dir,
+		Tilesets:  a.Tilesets,
+		Frames:    []Frame{},
+		Mode:      a.Mode,
+		LoopCount: a.LoopCount,
+		Anchor:    a.Anchor,
+	}
+}
+
+// mirrorFrames flips every part horizontally around the anchor of the animation.
+func mirrorFrames(frames []Frame) []Frame {
+	mirrored := make([]Frame, len(frames))
+	for i, f := range frames {
+		parts := make([]Part, len(f.Parts))
+		for j, p := range f.Parts {
+			p.FlipHorizontal = !p.FlipHorizontal
+			p.XOffset = -p.XOffset
+			parts[j] = p
+		}
+		mirrored[i] = Frame{Duration: f.Duration, Parts: parts, Events: f.Events}
+	}
+	return mirrored
+}
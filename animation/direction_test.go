@@ -0,0 +1,138 @@
+package animation
+
+import (
+	"strings"
+	"testing"
+)
+
+const directionalOnly = `
+animations:
+  - class: hero
+    action: walk
+    tilesets:
+      - hero
+    directions:
+      right:
+        simple:
+          duration: 200
+          frames: [1, 2]
+      left:
+        mirror: right
+`
+
+func loadAnimation(t *testing.T, source string) *Animation {
+	t.Helper()
+	animations, err := fileReader("test.ani", strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return animations.Animations[0]
+}
+
+func TestDirectionalOnlyAnimationPlays(t *testing.T) {
+	a := loadAnimation(t, directionalOnly)
+	if len(a.Frames) != 0 {
+		t.Fatalf("base animation has %d frames", len(a.Frames))
+	}
+
+	p := NewAnimationPlayer(a)
+	p.Update()
+	frame := p.GetCurrentFrame()
+	if len(frame.Parts) != 1 || frame.Parts[0].TileID != 1 {
+		t.Fatalf("default variant frame: %+v", frame)
+	}
+	if p.Animation.Direction != DirectionRight {
+		t.Errorf("default variant faces %q, want right", p.Animation.Direction)
+	}
+}
+
+func TestDefaultVariantPrefersDown(t *testing.T) {
+	a := &Animation{Variants: map[Direction]*Animation{
+		DirectionUp:   {Direction: DirectionUp, Frames: frames(1)},
+		DirectionDown: {Direction: DirectionDown, Frames: frames(1)},
+	}}
+	if got := a.DefaultVariant().Direction; got != DirectionDown {
+		t.Errorf("default variant faces %q, want down", got)
+	}
+}
+
+func TestEmptyAnimationHasEmptyFrame(t *testing.T) {
+	p := NewAnimationPlayer(&Animation{})
+	p.Update()
+	if frame := p.GetCurrentFrame(); len(frame.Parts) != 0 {
+		t.Errorf("frame of an empty animation: %+v", frame)
+	}
+}
+
+type resolverFunc func(class string, action string) (*Animation, error)
+
+func (f resolverFunc) GetAnimation(class string, action string) (*Animation, error) {
+	return f(class, action)
+}
+
+func TestStateControllerDirectionalOnly(t *testing.T) {
+	a := loadAnimation(t, directionalOnly)
+	sm := &StateMachine{
+		Class:   "hero",
+		Initial: "walk",
+		States:  []*State{{Name: "walk", Action: "walk"}},
+	}
+	if err := sm.decode(); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewStateController(sm, resolverFunc(func(string, string) (*Animation, error) { return a, nil }), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Player.Advance(0)
+	if err := sc.ForceState("walk"); err != nil {
+		t.Fatal(err)
+	}
+	if frame := sc.Player.GetCurrentFrame(); len(frame.Parts) == 0 {
+		t.Error("state controller plays an animation without frames")
+	}
+}
+
+func TestMirrorAroundAnchor(t *testing.T) {
+	a := loadAnimation(t, `
+animations:
+  - class: hero
+    action: walk
+    tilesets:
+      - hero
+    directions:
+      right:
+        simple:
+          duration: 100
+          defaults:
+            x_offset: 2
+            anchor: {x: 4, y: 16}
+          frames: [1]
+      left:
+        mirror: right
+      up:
+        mirror: left
+`)
+
+	right := a.Variants[DirectionRight]
+	left := a.Variants[DirectionLeft]
+	part := right.Frames[0].Parts[0]
+	mirrored := left.Frames[0].Parts[0]
+	if !mirrored.FlipHorizontal {
+		t.Error("mirrored part is not flipped")
+	}
+
+	// A 16 pixel tile covers -2..14 facing right, so -14..2 facing left
+	if got := right.PartPosition(part, 16); got.X != -2 || got.Y != -16 {
+		t.Errorf("right part at %v", got)
+	}
+	if got := left.PartPosition(mirrored, 16); got.X != -14 || got.Y != -16 {
+		t.Errorf("mirrored part at %v, want (-14,-16)", got)
+	}
+
+	up := a.Variants[DirectionUp]
+	if got := up.PartPosition(up.Frames[0].Parts[0], 16); got.X != -2 || up.Frames[0].Parts[0].FlipHorizontal {
+		t.Errorf("mirror of a mirror at %v, flipped %v", got, up.Frames[0].Parts[0].FlipHorizontal)
+	}
+}
@@ -0,0 +1,31 @@
+package animation
+
+import (
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Event is a named hook attached to a frame. Players invoke the registered handlers when the frame is entered.
+//
+// In an animation file an event is either just its name or a mapping with a name and properties
+//
+//	events:
+//	  - footstep
+//	  - name: spawn_projectile
+//	    properties:
+//	      speed: 4
+type Event struct {
+	Name       string         `yaml:"name" json:"name"`
+	Properties map[string]any `yaml:"properties" json:"properties,omitempty"`
+}
+
+func (e *Event) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&e.Name)
+	}
+
+	type event Event
+	return node.Decode((*event)(e))
+}
+
+// EventHandler is called by a player when a frame with an event is entered.
+type EventHandler func(p *AnimationPlayer, e Event)
@@ -3,22 +3,33 @@ package manager
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/talvor/tiled/animation"
-	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/animation/aseprite"
+	"github.com/talvor/tiled/clock"
+	"github.com/talvor/tiled/internal/pathutil"
+	"github.com/talvor/tiled/tsx"
 )
 
 var (
 	ErrAnimationNotFound    = errors.New("manager: animation not found")
 	ErrTilesetGroupNotFound = errors.New("manager: tileset group not found")
+	ErrStateMachineNotFound = errors.New("manager: state machine not found")
 )
 
 type AnimationManager struct {
 	baseDir       string
 	Animations    map[string]*animation.Animation
 	TilesetGroups map[string]*animation.TilesetGroup
+	StateMachines map[string]*animation.StateMachine
+	// Tilesets generated for imported sprite sheets, they must be registered with the tileset manager
+	Tilesets []*tsx.Tileset
+	// Clock is given to every player created by NewPlayer
+	Clock clock.Clock
 }
 
 func (am *AnimationManager) GetAnimation(class string, action string) (*animation.Animation, error) {
@@ -31,6 +42,59 @@ func (am *AnimationManager) GetAnimation(class string, action string) (*animatio
 	return ani, nil
 }
 
+// GetDirectionalAnimation returns the variant of the animation facing dir. Animations without
+// a matching variant are returned unchanged.
+func (am *AnimationManager) GetDirectionalAnimation(class string, action string, dir animation.Direction) (*animation.Animation, error) {
+	ani, err := am.GetAnimation(class, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return ani.Variant(dir), nil
+}
+
+// GetAnimationFacing returns the variant of the animation closest to the facing vector (dx, dy).
+// When eight is false only up, down, left and right variants are considered.
+func (am *AnimationManager) GetAnimationFacing(class string, action string, dx, dy float64, eight bool) (*animation.Animation, error) {
+	return am.GetDirectionalAnimation(class, action, animation.DirectionFromVector(dx, dy, eight))
+}
+
+// NewPlayer returns a new player for the animation. Every entity drawing the animation should own its player.
+func (am *AnimationManager) NewPlayer(class string, action string) (*animation.AnimationPlayer, error) {
+	ani, err := am.GetAnimation(class, action)
+	if err != nil {
+		return nil, err
+	}
+
+	p := animation.NewAnimationPlayer(ani)
+	if am.Clock != nil {
+		p.Clock = am.Clock
+	}
+	return p, nil
+}
+
+func (am *AnimationManager) GetStateMachine(class string) (*animation.StateMachine, error) {
+	sm, ok := am.StateMachines[class]
+	if !ok {
+		return nil, fmt.Errorf("class:%s %w", class, ErrStateMachineNotFound)
+	}
+	return sm, nil
+}
+
+// NewStateController returns a controller running the state machine of the class.
+// Every entity should own its controller.
+func (am *AnimationManager) NewStateController(class string) (*animation.StateController, error) {
+	sm, err := am.GetStateMachine(class)
+	if err != nil {
+		return nil, err
+	}
+	return animation.NewStateController(sm, am, am.Clock)
+}
+
+func (am *AnimationManager) SetClock(c clock.Clock) {
+	am.Clock = c
+}
+
 func (am *AnimationManager) GetTilesetGroup(name string) (*animation.TilesetGroup, error) {
 	group, ok := am.TilesetGroups[name]
 	if !ok {
@@ -39,42 +103,84 @@ func (am *AnimationManager) GetTilesetGroup(name string) (*animation.TilesetGrou
 	return group, nil
 }
 
+// Validate checks every loaded animation against the tilesets of the resolver, eg. a
+// tsx/manager.TilesetManager. It returns animation.ValidationErrors listing every problem, or nil.
+func (am *AnimationManager) Validate(resolver animation.TilesetResolver) error {
+	names := slices.Sorted(maps.Keys(am.Animations))
+
+	var errs animation.ValidationErrors
+	for _, name := range names {
+		if err := am.Animations[name].Validate(resolver); err != nil {
+			errs = append(errs, err.(animation.ValidationErrors)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
 func (am *AnimationManager) DebugPrintAnimations() {
 	for name := range am.Animations {
 		fmt.Println(name)
 	}
 }
 
+// NewManager loads the animations, sprite sheets and state machines of the directories and prints the
+// problems found, see LoadManager.
 func NewManager(baseDirs []string) *AnimationManager {
+	am, err := LoadManager(baseDirs)
+	if err != nil {
+		fmt.Printf("Error loading animations: %v\n", err)
+	}
+	return am
+}
+
+// LoadManager loads the animations, sprite sheets and state machines of the directories. Files with
+// problems, and state machines whose actions have no animation, are left out and every problem found
+// is returned. The manager is usable even when an error is returned.
+func LoadManager(baseDirs []string) (*AnimationManager, error) {
 	am := &AnimationManager{
 		Animations:    make(map[string]*animation.Animation),
 		TilesetGroups: make(map[string]*animation.TilesetGroup),
+		StateMachines: make(map[string]*animation.StateMachine),
+		Clock:         clock.Default,
 	}
 
-	// Load animations from the base directories
+	var errs []error
 	for _, baseDir := range baseDirs {
-		if err := common.PathShouldBeDirectory(baseDir); err != nil {
-			fmt.Printf("Error: %s %v\n", baseDir, err)
+		if err := pathutil.PathShouldBeDirectory(baseDir); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", baseDir, err))
 			continue
 		}
 
-		if err := loadAnimations(am, baseDir); err != nil {
-			fmt.Printf("Error loading animations from %s: %v\n", baseDir, err)
+		errs = append(errs, loadAnimations(am, baseDir)...)
+	}
+
+	// State machines may use the animations of any directory, check them once everything is loaded
+	for _, class := range slices.Sorted(maps.Keys(am.StateMachines)) {
+		if err := am.StateMachines[class].CheckActions(am); err != nil {
+			errs = append(errs, err)
+			delete(am.StateMachines, class)
 		}
 	}
 
-	return am
+	return am, errors.Join(errs...)
 }
 
-func loadAnimations(am *AnimationManager, baseDir string) error {
-	aniFiles, err := findANIFiles(baseDir)
+// loadAnimations loads every file of the directory, a file with problems does not stop the others loading.
+func loadAnimations(am *AnimationManager, baseDir string) []error {
+	var errs []error
+
+	aniFiles, err := findFiles(baseDir, ".ani")
 	if err != nil {
-		return fmt.Errorf("error loading animations: %s %w", baseDir, err)
+		return []error{fmt.Errorf("error loading animations: %s %w", baseDir, err)}
 	}
 	for _, aniFile := range aniFiles {
 		animations, err := animation.LoadFile(aniFile)
 		if err != nil {
-			return fmt.Errorf("error loading animations: %s %w", baseDir, err)
+			errs = append(errs, fmt.Errorf("error loading animations: %s %w", aniFile, err))
+			continue
 		}
 
 		for _, animation := range animations.Animations {
@@ -87,18 +193,65 @@ func loadAnimations(am *AnimationManager, baseDir string) error {
 		}
 	}
 
+	jsonFiles, err := findFiles(baseDir, ".json")
+	if err != nil {
+		return append(errs, fmt.Errorf("error loading sprite sheets: %s %w", baseDir, err))
+	}
+	for _, jsonFile := range jsonFiles {
+		if err := loadSpriteSheet(am, jsonFile); err != nil && !errors.Is(err, aseprite.ErrNotAseprite) {
+			errs = append(errs, fmt.Errorf("error loading sprite sheets: %s %w", jsonFile, err))
+		}
+	}
+
+	fsmFiles, err := findFiles(baseDir, ".fsm")
+	if err != nil {
+		return append(errs, fmt.Errorf("error loading state machines: %s %w", baseDir, err))
+	}
+	for _, fsmFile := range fsmFiles {
+		sms, err := animation.LoadStateMachineFile(fsmFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error loading state machines: %s %w", fsmFile, err))
+			continue
+		}
+
+		for _, sm := range sms.StateMachines {
+			am.StateMachines[sm.Class] = sm
+		}
+	}
+
+	return errs
+}
+
+// loadSpriteSheet imports the animations and tileset of an Aseprite sprite sheet.
+func loadSpriteSheet(am *AnimationManager, jsonFile string) error {
+	sheet, err := aseprite.LoadFile(jsonFile)
+	if err != nil {
+		return err
+	}
+
+	animations, ts, err := sheet.Import(aseprite.Options{})
+	if err != nil {
+		return err
+	}
+
+	for _, animation := range animations.Animations {
+		name := makeAnimationName(animation.Class, animation.Action)
+		am.Animations[name] = animation
+	}
+	am.Tilesets = append(am.Tilesets, ts)
+
 	return nil
 }
 
-func findANIFiles(dir string) ([]string, error) {
-	var aniFiles []string
+func findFiles(dir string, ext string) ([]string, error) {
+	var files []string
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() && filepath.Ext(path) == ".ani" {
-			aniFiles = append(aniFiles, path)
+		if !info.IsDir() && filepath.Ext(path) == ext {
+			files = append(files, path)
 		}
 		return nil
 	})
@@ -106,7 +259,7 @@ func findANIFiles(dir string) ([]string, error) {
 		return nil, err
 	}
 
-	return aniFiles, nil
+	return files, nil
 }
 
 func makeAnimationName(class string, action string) string {
@@ -0,0 +1,160 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/talvor/tiled/animation"
+	"github.com/talvor/tiled/tsx"
+)
+
+// copyFile copies a file of the example directory into dir under a new name.
+func copyFile(t *testing.T, example string, dir string, name string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "example", example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExampleStateMachineActions(t *testing.T) {
+	dir := t.TempDir()
+	copyFile(t, "simple-animation.yaml", dir, "simple.ani")
+	copyFile(t, "player.fsm", dir, "player.fsm")
+
+	am := NewManager([]string{dir})
+	if _, err := am.GetStateMachine("simple_player"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.NewStateController("simple_player"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStateMachineWithMissingActionIsDropped(t *testing.T) {
+	dir := t.TempDir()
+	copyFile(t, "simple-animation.yaml", dir, "simple.ani")
+	fsm := `state_machines:
+  - class: simple_player
+    initial: idle
+    states:
+      - name: idle
+        action: idle
+      - name: jump
+        action: jump
+`
+	if err := os.WriteFile(filepath.Join(dir, "player.fsm"), []byte(fsm), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	am := NewManager([]string{dir})
+	if _, err := am.GetStateMachine("simple_player"); err == nil {
+		t.Fatal("state machine with a missing action was registered")
+	}
+}
+
+func TestLoadManagerReturnsErrors(t *testing.T) {
+	dir := t.TempDir()
+	copyFile(t, "simple-animation.yaml", dir, "simple.ani")
+	broken := `animations:
+  - class: broken
+    action: idle
+    mode: sideways
+    tilesets: [broken]
+    simple:
+      duration: 100
+      frames: [0]
+`
+	if err := os.WriteFile(filepath.Join(dir, "broken.ani"), []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	am, err := LoadManager([]string{dir})
+	if !errors.Is(err, animation.ErrUnknownMode) {
+		t.Fatalf("got %v, want the unknown mode", err)
+	}
+	if _, err := am.GetAnimation("simple_player", "walk"); err != nil {
+		t.Errorf("valid file was not loaded: %v", err)
+	}
+	if _, err := am.GetAnimation("broken", "idle"); err == nil {
+		t.Error("invalid file was loaded")
+	}
+}
+
+func TestNewPlayerWithoutClock(t *testing.T) {
+	dir := t.TempDir()
+	copyFile(t, "simple-animation.yaml", dir, "simple.ani")
+
+	am := NewManager([]string{dir})
+	am.SetClock(nil)
+	p, err := am.NewPlayer("simple_player", "idle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Clock == nil {
+		t.Fatal("player of a manager without a clock has no clock")
+	}
+	p.Update()
+}
+
+// tilesets resolves tilesets by name.
+type tilesets map[string]*tsx.Tileset
+
+func (t tilesets) GetTilesetByName(name string) *tsx.Tileset {
+	return t[name]
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	hero := `animations:
+  - class: hero
+    action: walk
+    tilesets: [hero]
+    simple:
+      duration: 200
+      frames: [0, 9]
+  - class: hero
+    action: idle
+    tilesets: [hero, cape]
+    simple:
+      duration: 100
+      frames: [0]
+`
+	if err := os.WriteFile(filepath.Join(dir, "hero.ani"), []byte(hero), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	am, err := LoadManager([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = am.Validate(tilesets{"hero": {Name: "hero", TileCount: 4}})
+	var errs animation.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got %v, want 2 validation errors", err)
+	}
+	for _, want := range []struct {
+		action string
+		err    error
+	}{
+		{"idle", animation.ErrTilesetNotFound},
+		{"walk", animation.ErrTileNotFound},
+	} {
+		if !slices.ContainsFunc(errs, func(e *animation.ValidationError) bool {
+			return e.Action == want.action && errors.Is(e, want.err)
+		}) {
+			t.Errorf("%s: %v not reported:\n%v", want.action, want.err, err)
+		}
+	}
+
+	err = am.Validate(tilesets{"hero": {Name: "hero", TileCount: 10}, "cape": {Name: "cape", TileCount: 1}})
+	if err != nil {
+		t.Errorf("got %v with every tileset loaded", err)
+	}
+}
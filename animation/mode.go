@@ -0,0 +1,33 @@
+package animation
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownMode = errors.New("animation: unknown playback mode")
+
+// Mode controls what a player does when it reaches the end of an animation.
+type Mode string
+
+const (
+	// ModeLoop restarts from the first frame, forever or LoopCount times
+	ModeLoop Mode = "loop"
+	// ModeOnce plays the animation once, after which nothing is drawn
+	ModeOnce Mode = "once"
+	// ModePingPong plays forward then backward, forever or LoopCount times ending on the first frame
+	ModePingPong Mode = "pingpong"
+	// ModeHold plays the animation once and keeps showing the last frame
+	ModeHold Mode = "hold"
+)
+
+func (a *Animation) decodeMode() error {
+	switch a.Mode {
+	case "":
+		a.Mode = ModeLoop
+	case ModeLoop, ModeOnce, ModePingPong, ModeHold:
+	default:
+		return fmt.Errorf("mode:%s %w", a.Mode, ErrUnknownMode)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package animation
+
+import (
+	"time"
+
+	"github.com/talvor/tiled/clock"
+)
+
+// AnimationPlayer plays an animation for a single entity. Each player keeps its own frame index,
+// clock and speed, so any number of entities can share the same Animation.
+type AnimationPlayer struct {
+	Animation *Animation
+	// Speed scales the playback rate, 1 is normal speed
+	Speed float64
+	// Clock drives Update, it defaults to clock.Default
+	Clock clock.Clock
+
+	frame      int
+	direction  int
+	loops      int
+	finished   bool
+	elapsed    time.Duration
+	lastTick   time.Duration
+	started    bool
+	entered    bool
+	onFinished []func(p *AnimationPlayer)
+	onEvent    map[string][]EventHandler
+}
+
+// NewAnimationPlayer returns a player for the animation, directional animations without frames of their own
+// play their default variant.
+func NewAnimationPlayer(a *Animation) *AnimationPlayer {
+	return &AnimationPlayer{
+		Animation: a.DefaultVariant(),
+		Speed:     1,
+		Clock:     clock.Default,
+		direction: 1,
+	}
+}
+
+// SetAnimation switches the player to another animation and restarts playback.
+// Setting the animation already playing does nothing.
+func (p *AnimationPlayer) SetAnimation(a *Animation) {
+	a = a.DefaultVariant()
+	if p.Animation == a {
+		return
+	}
+	p.Animation = a
+	p.Reset()
+}
+
+// SetAnimationKeepPhase switches the player to another animation at the same relative position,
+// eg. to change the facing of a walk cycle without restarting it.
+func (p *AnimationPlayer) SetAnimationKeepPhase(a *Animation) {
+	a = a.DefaultVariant()
+	if p.Animation == a {
+		return
+	}
+	progress := p.Progress()
+	started, lastTick := p.started, p.lastTick
+
+	p.Animation = a
+	p.Reset()
+	p.Seek(progress)
+	p.started, p.lastTick = started, lastTick
+}
+
+// Reset restarts playback from the first frame.
+func (p *AnimationPlayer) Reset() {
+	p.frame = 0
+	p.direction = 1
+	p.loops = 0
+	p.finished = false
+	p.elapsed = 0
+	p.started = false
+	p.entered = false
+}
+
+// OnFinished registers a callback called when the animation finishes. Animations finish when
+// their mode is once or hold, or when they have a loop count.
+func (p *AnimationPlayer) OnFinished(callback func(p *AnimationPlayer)) {
+	p.onFinished = append(p.onFinished, callback)
+}
+
+// OnEvent registers a handler for the named frame event. An empty name receives every event.
+func (p *AnimationPlayer) OnEvent(name string, handler EventHandler) {
+	if p.onEvent == nil {
+		p.onEvent = make(map[string][]EventHandler)
+	}
+	p.onEvent[name] = append(p.onEvent[name], handler)
+}
+
+// Finished reports whether playback has ended.
+func (p *AnimationPlayer) Finished() bool {
+	return p.finished
+}
+
+// Visible reports whether the current frame should be drawn. Animations in once mode are
+// hidden after they finish.
+func (p *AnimationPlayer) Visible() bool {
+	return !(p.finished && p.Animation.Mode == ModeOnce)
+}
+
+// Update advances the player by the time its clock moved since the previous call.
+// The first call starts playback.
+func (p *AnimationPlayer) Update() {
+	now := p.Clock.Now()
+	if p.started {
+		p.Advance(now - p.lastTick)
+	} else {
+		p.Advance(0)
+	}
+	p.lastTick = now
+	p.started = true
+}
+
+// Advance moves playback forward by dt scaled by the player speed. The events of every frame
+// entered are fired in order, including frames passed over by a long dt.
+func (p *AnimationPlayer) Advance(dt time.Duration) {
+	if p.Animation == nil || len(p.Animation.Frames) == 0 {
+		return
+	}
+	if !p.entered {
+		p.entered = true
+		p.fireEvents()
+	}
+	if p.totalDuration() <= 0 {
+		return
+	}
+
+	p.elapsed += time.Duration(float64(dt) * p.Speed)
+	for !p.finished {
+		d := p.frameDuration(p.frame)
+		if p.elapsed < d {
+			return
+		}
+		p.elapsed -= d
+		p.step()
+	}
+}
+
+// step moves to the next frame according to the playback mode.
+func (p *AnimationPlayer) step() {
+	a := p.Animation
+	count := len(a.Frames)
+
+	if a.Mode == ModePingPong && count > 1 {
+		if p.direction == 0 {
+			p.direction = 1
+		}
+		next := p.frame + p.direction
+		if next < 0 || next >= count {
+			p.direction = -p.direction
+			next = p.frame + p.direction
+		}
+		p.frame = next
+		p.fireEvents()
+
+		// A cycle ends when playback returns to the first frame
+		if p.frame == 0 {
+			p.loops++
+			if a.LoopCount > 0 && p.loops >= a.LoopCount {
+				p.finish()
+			}
+		}
+		return
+	}
+
+	if p.frame+1 < count {
+		p.frame++
+		p.fireEvents()
+		return
+	}
+
+	p.loops++
+	if a.Mode == ModeOnce || a.Mode == ModeHold || (a.LoopCount > 0 && p.loops >= a.LoopCount) {
+		p.finish()
+		return
+	}
+	p.frame = 0
+	p.fireEvents()
+}
+
+func (p *AnimationPlayer) fireEvents() {
+	if len(p.onEvent) == 0 {
+		return
+	}
+	for _, e := range p.Animation.Frames[p.frame].Events {
+		for _, handler := range p.onEvent[e.Name] {
+			handler(p, e)
+		}
+		for _, handler := range p.onEvent[""] {
+			handler(p, e)
+		}
+	}
+}
+
+func (p *AnimationPlayer) finish() {
+	p.finished = true
+	p.elapsed = 0
+	for _, callback := range p.onFinished {
+		callback(p)
+	}
+}
+
+// Loops returns the number of times the animation has played to the end since it started.
+func (p *AnimationPlayer) Loops() int {
+	return p.loops
+}
+
+// Progress returns the position of playback within the animation between 0 and 1.
+func (p *AnimationPlayer) Progress() float64 {
+	total := p.totalDuration()
+	if total <= 0 {
+		return 0
+	}
+
+	var position time.Duration
+	for i := range p.frame {
+		position += p.frameDuration(i)
+	}
+	return float64(position+p.elapsed) / float64(total)
+}
+
+// Seek moves playback to a position between 0 and 1 of the animation without firing frame events.
+func (p *AnimationPlayer) Seek(progress float64) {
+	total := p.totalDuration()
+	position := time.Duration(progress * float64(total))
+
+	p.frame = 0
+	p.elapsed = 0
+	p.entered = true
+	for i := range p.Animation.Frames {
+		d := p.frameDuration(i)
+		if position < d || i == len(p.Animation.Frames)-1 {
+			p.frame = i
+			p.elapsed = min(position, max(d-1, 0))
+			return
+		}
+		position -= d
+	}
+}
+
+// FrameIndex returns the index of the current frame.
+func (p *AnimationPlayer) FrameIndex() int {
+	return p.frame
+}
+
+// GetCurrentFrame returns the current frame, an empty frame when the animation has no frames.
+func (p *AnimationPlayer) GetCurrentFrame() Frame {
+	if p.Animation == nil || p.frame >= len(p.Animation.Frames) {
+		return Frame{}
+	}
+	return p.Animation.Frames[p.frame]
+}
+
+// GetTileID returns the tile id of a part of the current frame or -1 when the part does not exist.
+func (p *AnimationPlayer) GetTileID(part int) int {
+	frame := p.GetCurrentFrame()
+
+	if part < 0 || part >= len(frame.Parts) {
+		return -1
+	}
+	return frame.Parts[part].TileID
+}
+
+func (p *AnimationPlayer) frameDuration(idx int) time.Duration {
+	return time.Duration(p.Animation.Frames[idx].Duration) * time.Millisecond
+}
+
+func (p *AnimationPlayer) totalDuration() time.Duration {
+	var total time.Duration
+	for i := range p.Animation.Frames {
+		total += p.frameDuration(i)
+	}
+	return total
+}
@@ -0,0 +1,69 @@
+package animation
+
+import (
+	"testing"
+	"time"
+)
+
+// frames returns count frames of 100ms whose single part shows the tile of the frame index.
+func frames(count int) []Frame {
+	f := make([]Frame, count)
+	for i := range f {
+		f[i] = Frame{Duration: 100, Parts: []Part{{TileID: i}}}
+	}
+	return f
+}
+
+func TestPlayerLoopCountEnds(t *testing.T) {
+	tests := []struct {
+		mode  Mode
+		loops int
+		frame int
+	}{
+		{ModeLoop, 2, 2},
+		{ModePingPong, 1, 0},
+		{ModeHold, 0, 2},
+	}
+
+	for _, tt := range tests {
+		p := NewAnimationPlayer(&Animation{Frames: frames(3), Mode: tt.mode, LoopCount: tt.loops})
+		finished := 0
+		p.OnFinished(func(*AnimationPlayer) { finished++ })
+
+		p.Advance(10 * time.Second)
+		if !p.Finished() || finished != 1 {
+			t.Errorf("%s: finished %v, callbacks %d", tt.mode, p.Finished(), finished)
+		}
+		if p.FrameIndex() != tt.frame {
+			t.Errorf("%s: finished on frame %d, want %d", tt.mode, p.FrameIndex(), tt.frame)
+		}
+	}
+}
+
+func TestPlayerPingPongOrder(t *testing.T) {
+	p := NewAnimationPlayer(&Animation{Frames: frames(3), Mode: ModePingPong})
+
+	var got []int
+	for range 6 {
+		got = append(got, p.FrameIndex())
+		p.Advance(100 * time.Millisecond)
+	}
+	want := []int{0, 1, 2, 1, 0, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("frames %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPlayerOnceHidesWhenFinished(t *testing.T) {
+	p := NewAnimationPlayer(&Animation{Frames: frames(2), Mode: ModeOnce})
+	p.Advance(150 * time.Millisecond)
+	if !p.Visible() {
+		t.Fatal("hidden before finishing")
+	}
+	p.Advance(100 * time.Millisecond)
+	if p.Visible() {
+		t.Error("visible after finishing")
+	}
+}
@@ -0,0 +1,79 @@
+package renderer
+
+import (
+	"image/color"
+
+	"github.com/talvor/tiled/animation"
+)
+
+// Appearance changes how the layers of an animation are drawn for a single entity, eg. hide the
+// helmet or tint the armour by team color. A part belongs to its layer, or when it has none to the
+// tileset it is drawn from.
+type Appearance struct {
+	layers map[string]*layerStyle
+}
+
+type layerStyle struct {
+	hidden  bool
+	tint    color.Color
+	opacity float64
+	tileset string
+}
+
+func NewAppearance() *Appearance {
+	return &Appearance{
+		layers: make(map[string]*layerStyle),
+	}
+}
+
+func (ap *Appearance) layer(name string) *layerStyle {
+	style, ok := ap.layers[name]
+	if !ok {
+		style = &layerStyle{opacity: 1}
+		ap.layers[name] = style
+	}
+	return style
+}
+
+func (ap *Appearance) Hide(layer string) {
+	ap.layer(layer).hidden = true
+}
+
+func (ap *Appearance) Show(layer string) {
+	ap.layer(layer).hidden = false
+}
+
+// SetTint multiplies the color of the layer by tint, nil removes the tint.
+func (ap *Appearance) SetTint(layer string, tint color.Color) {
+	ap.layer(layer).tint = tint
+}
+
+// SetOpacity sets the opacity of the layer from 0 to 1.
+func (ap *Appearance) SetOpacity(layer string, opacity float64) {
+	ap.layer(layer).opacity = max(0, min(1, opacity))
+}
+
+// SwapTileset draws the layer from another tileset with the same layout, eg. for equipment.
+// An empty tileset restores the tileset of the animation.
+func (ap *Appearance) SwapTileset(layer string, tileset string) {
+	ap.layer(layer).tileset = tileset
+}
+
+// Reset removes every change to the layer.
+func (ap *Appearance) Reset(layer string) {
+	delete(ap.layers, layer)
+}
+
+// style returns the changes for the layer of a part, or the first of the names it is drawn with,
+// eg. its tileset. It returns nil when there are none.
+func (ap *Appearance) style(part animation.Part, names ...string) *layerStyle {
+	if ap == nil {
+		return nil
+	}
+	for _, name := range append([]string{part.Layer}, names...) {
+		if style, ok := ap.layers[name]; ok && name != "" {
+			return style
+		}
+	}
+	return nil
+}
@@ -34,29 +34,62 @@ func (r *Renderer) SetTilesetResolver(resolver TilesetResolver) {
 	r.TilesetResolver = resolver
 }
 
-func (r *Renderer) Draw(class string, action string, opts *common.DrawOptions) error {
-	ani, err := r.AnimationManager.GetAnimation(class, action)
-	if err != nil {
-		return err
+// Draw advances the player and draws its current frame.
+func (r *Renderer) Draw(player *animation.AnimationPlayer, opts *common.DrawOptions) error {
+	return r.DrawWithAppearance(player, nil, opts)
+}
+
+// DrawWithAppearance advances the player and draws its current frame with the layer changes of
+// the appearance applied. A nil appearance draws the animation unchanged.
+func (r *Renderer) DrawWithAppearance(player *animation.AnimationPlayer, appearance *Appearance, opts *common.DrawOptions) error {
+	return r.DrawPaperDoll(player, nil, appearance, opts)
+}
+
+// DrawPaperDoll advances the player and draws its current frame from the equipped slots of the
+// paper doll instead of the tilesets of the animation, in the draw order of the animation direction.
+// Parts whose layer is a slot are drawn from that slot only. Appearance changes apply to layers,
+// slots and tilesets. Either of doll and appearance can be nil.
+func (r *Renderer) DrawPaperDoll(player *animation.AnimationPlayer, doll *tsxr.PaperDoll, appearance *Appearance, opts *common.DrawOptions) error {
+	player.Update()
+	if !player.Visible() {
+		return nil
+	}
+
+	ani := player.Animation
+	frame := player.GetCurrentFrame()
+	if len(frame.Parts) == 0 {
+		return nil
 	}
-	frame := ani.GetNextFrame()
 
 	drawFunc := func(part animation.Part) error {
-		opts.OffsetX = float64(part.XOffset)
-		opts.OffsetY = float64(part.YOffset)
 		opts.FlipHorizontal = part.FlipHorizontal
 		opts.FlipVertical = part.FlipVertical
 
-		if part.Tileset != "" {
-			if err := r.drawTile(part.Tileset, part.TileID, opts); err != nil {
-				return err
+		if doll != nil && doll.HasSlot(part.Layer) {
+			slot := doll.Slot(part.Layer)
+			if slot.Tileset == "" {
+				return nil
 			}
-		} else {
-			for _, tileset := range ani.Tilesets {
-				if err := r.drawTile(tileset, part.TileID, opts); err != nil {
+			return r.drawTile(ani, part, slot.Tileset, slot.Palette, appearance.style(part, slot.Tileset), opts)
+		}
+
+		if part.Tileset != "" {
+			return r.drawTile(ani, part, part.Tileset, nil, appearance.style(part, part.Tileset), opts)
+		}
+
+		if doll != nil {
+			for _, slot := range doll.Layers(string(ani.Direction)) {
+				if err := r.drawTile(ani, part, slot.Tileset, slot.Palette, appearance.style(part, slot.Name, slot.Tileset), opts); err != nil {
 					return err
 				}
 			}
+			return nil
+		}
+
+		for _, tileset := range ani.Tilesets {
+			if err := r.drawTile(ani, part, tileset, nil, appearance.style(part, tileset), opts); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -78,6 +111,12 @@ func (r *Renderer) processFrame(frame *animation.Frame, processFunc func(part an
 }
 
 func (r *Renderer) GetCollider(class string, action string, colliderName string) *image.Rectangle {
+	if ani, err := r.AnimationManager.GetAnimation(class, action); err == nil {
+		if rect, ok := ani.Colliders[colliderName]; ok {
+			return &rect
+		}
+	}
+
 	// If the tileset resolver is not set, we cannot resolve tilesets to get the colliders
 	if r.TilesetResolver == nil {
 		return nil
@@ -119,17 +158,43 @@ func (r *Renderer) GetCollider(class string, action string, colliderName string)
 	return ani.ColliderRect
 }
 
-func (r *Renderer) drawTile(tileset string, tileID int, opts *common.DrawOptions) error {
+func (r *Renderer) drawTile(ani *animation.Animation, part animation.Part, tileset string, palette *tsxr.Palette, style *layerStyle, opts *common.DrawOptions) error {
+	if style != nil {
+		if style.hidden || style.opacity == 0 {
+			return nil
+		}
+		if style.tileset != "" {
+			tileset = style.tileset
+		}
+	}
+
+	ts := r.TSXRenderer.TilesetManager.GetTilesetByName(tileset)
+	if ts == nil {
+		return fmt.Errorf("failed to draw tile %s: %w", tileset, tsxr.ErrTileset)
+	}
+
+	pos := ani.PartPosition(part, ts.TileWidth)
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Concat(opts.Op.GeoM)
-	op.GeoM.Translate(opts.OffsetX, opts.OffsetY)
+	op.GeoM.Translate(float64(pos.X), float64(pos.Y))
+	op.ColorScale = opts.Op.ColorScale
+
+	if style != nil {
+		if style.tint != nil {
+			// Tint the color channels only, the tileset images use premultiplied alpha
+			cr, cg, cb, _ := style.tint.RGBA()
+			op.ColorScale.Scale(float32(cr)/0xffff, float32(cg)/0xffff, float32(cb)/0xffff, 1)
+		}
+		op.ColorScale.ScaleAlpha(float32(style.opacity))
+	}
+
 	drawOptions := &common.DrawOptions{
 		Screen:         opts.Screen,
 		Op:             op,
 		FlipHorizontal: opts.FlipHorizontal,
 		FlipVertical:   opts.FlipVertical,
 	}
-	if err := r.TSXRenderer.DrawTileWithName(tileset, uint32(tileID), drawOptions); err != nil {
+	if err := r.TSXRenderer.DrawTileWithPalette(ts, uint32(part.TileID), palette, drawOptions); err != nil {
 		return fmt.Errorf("failed to draw tile %s: %w", tileset, err)
 	}
 	return nil
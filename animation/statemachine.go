@@ -0,0 +1,245 @@
+package animation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownState     = errors.New("animation: unknown state")
+	ErrInvalidCondition = errors.New("animation: invalid transition condition")
+	ErrUnknownAction    = errors.New("animation: state action has no animation")
+)
+
+// AnyState can be used as the from state of a transition to leave any state.
+const AnyState = "*"
+
+// StateMachine maps the states of an entity class to animation actions.
+// It is shared by every entity of the class, runtime state lives in a StateController.
+type StateMachine struct {
+	// Source is the file the state machine was loaded from
+	Source      string         `yaml:"-" json:"-"`
+	Class       string         `yaml:"class" json:"class"`
+	Initial     string         `yaml:"initial" json:"initial"`
+	Parameters  map[string]any `yaml:"parameters" json:"parameters,omitempty"`
+	States      []*State       `yaml:"states" json:"states"`
+	Transitions []*Transition  `yaml:"transitions" json:"transitions"`
+}
+
+type State struct {
+	Name   string `yaml:"name" json:"name"`
+	Action string `yaml:"action" json:"action"`
+	// Interruptible states can be left before their animation finishes. Defaults to true.
+	Interruptible *bool `yaml:"interruptible" json:"interruptible,omitempty"`
+}
+
+func (s *State) interruptible() bool {
+	return s.Interruptible == nil || *s.Interruptible
+}
+
+// Transition moves from one state to another when all of its conditions hold.
+//
+// Conditions are written as "param", "!param" or "param op value" where op is one of
+// ==, !=, <, <=, > or >=.
+type Transition struct {
+	From string   `yaml:"from" json:"from"`
+	To   string   `yaml:"to" json:"to"`
+	When []string `yaml:"when" json:"when,omitempty"`
+	// Wait delays the transition until the animation of the from state has finished, or completed a loop
+	Wait bool `yaml:"wait" json:"wait,omitempty"`
+	// Interrupt allows the transition to leave a state that is not interruptible
+	Interrupt bool `yaml:"interrupt" json:"interrupt,omitempty"`
+	// Sync starts the new animation at the same relative position as the old one, eg. to keep
+	// the walk cycle phase when changing direction
+	Sync bool `yaml:"sync" json:"sync,omitempty"`
+
+	conditions []condition
+}
+
+type StateMachines struct {
+	Source        string          `json:"source"`
+	StateMachines []*StateMachine `yaml:"state_machines" json:"state_machines"`
+}
+
+func (sm *StateMachine) GetState(name string) (*State, error) {
+	for _, s := range sm.States {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("class:%s state:%s %w", sm.Class, name, ErrUnknownState)
+}
+
+func (sm *StateMachine) decode() error {
+	if _, err := sm.GetState(sm.Initial); err != nil {
+		return err
+	}
+
+	for _, t := range sm.Transitions {
+		if t.From != AnyState {
+			if _, err := sm.GetState(t.From); err != nil {
+				return err
+			}
+		}
+		if _, err := sm.GetState(t.To); err != nil {
+			return err
+		}
+
+		t.conditions = make([]condition, 0, len(t.When))
+		for _, w := range t.When {
+			c, err := parseCondition(w)
+			if err != nil {
+				return fmt.Errorf("class:%s transition:%s->%s %w", sm.Class, t.From, t.To, err)
+			}
+			t.conditions = append(t.conditions, c)
+		}
+	}
+	return nil
+}
+
+// CheckActions reports every state whose action has no animation. The resolver is eg. the
+// animation manager the state machine was loaded with. It returns ValidationErrors, or nil.
+func (sm *StateMachine) CheckActions(resolver AnimationResolver) error {
+	var errs ValidationErrors
+	for _, s := range sm.States {
+		if _, err := resolver.GetAnimation(sm.Class, s.Action); err != nil {
+			errs = append(errs, &ValidationError{
+				File:   sm.Source,
+				Class:  sm.Class,
+				Action: s.Action,
+				Frame:  -1,
+				Part:   -1,
+				Err:    fmt.Errorf("state:%s %w", s.Name, ErrUnknownAction),
+			})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+type condition struct {
+	param  string
+	op     string
+	value  any
+	negate bool
+}
+
+func parseCondition(s string) (condition, error) {
+	fields := strings.Fields(s)
+	switch len(fields) {
+	case 1:
+		if name, ok := strings.CutPrefix(fields[0], "!"); ok {
+			return condition{param: name, negate: true}, nil
+		}
+		return condition{param: fields[0]}, nil
+	case 3:
+		switch fields[1] {
+		case "==", "!=", "<", "<=", ">", ">=":
+			return condition{param: fields[0], op: fields[1], value: parseValue(fields[2])}, nil
+		}
+	}
+	return condition{}, fmt.Errorf("condition:%q %w", s, ErrInvalidCondition)
+}
+
+func parseValue(s string) any {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return strings.Trim(s, `"'`)
+}
+
+func (c condition) eval(params map[string]any) bool {
+	v := params[c.param]
+	if c.op == "" {
+		return truthy(v) != c.negate
+	}
+
+	if a, ok := toFloat(v); ok {
+		if b, ok := toFloat(c.value); ok {
+			switch c.op {
+			case "==":
+				return a == b
+			case "!=":
+				return a != b
+			case "<":
+				return a < b
+			case "<=":
+				return a <= b
+			case ">":
+				return a > b
+			case ">=":
+				return a >= b
+			}
+		}
+	}
+
+	switch c.op {
+	case "==":
+		return v == c.value
+	case "!=":
+		return v != c.value
+	}
+	return false
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case nil:
+		return false
+	}
+	f, ok := toFloat(v)
+	return ok && f != 0
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func stateMachineReader(source string, r io.Reader) (*StateMachines, error) {
+	sms := &StateMachines{Source: source}
+	if err := yaml.NewDecoder(r).Decode(sms); err != nil {
+		return nil, err
+	}
+
+	for _, sm := range sms.StateMachines {
+		sm.Source = source
+		if err := sm.decode(); err != nil {
+			return nil, fmt.Errorf("file:%s %w", source, err)
+		}
+	}
+	return sms, nil
+}
+
+// LoadStateMachineFile loads the state machines defined in a YAML file
+func LoadStateMachineFile(fileName string) (*StateMachines, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return stateMachineReader(fileName, f)
+}
@@ -0,0 +1,182 @@
+package animation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/talvor/tiled/tsx"
+)
+
+var (
+	ErrNoFrames            = errors.New("animation: animation has no frames")
+	ErrInvalidTilesetIndex = errors.New("animation: tileset index out of range")
+	ErrTilesetNotFound     = errors.New("animation: tileset not found")
+	ErrTileNotFound        = errors.New("animation: tile id out of range of tileset")
+	ErrInvalidDuration     = errors.New("animation: frame duration must be positive")
+)
+
+// TilesetResolver finds a loaded tileset by name, eg. tsx/manager.TilesetManager.
+type TilesetResolver interface {
+	GetTilesetByName(name string) *tsx.Tileset
+}
+
+// ValidationError is a problem with an animation. Frame and Part are -1 when the error is not
+// specific to a frame or part.
+type ValidationError struct {
+	File      string
+	Class     string
+	Action    string
+	Direction Direction
+	Frame     int
+	Part      int
+	Err       error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		fmt.Fprintf(&b, "file:%s ", e.File)
+	}
+	fmt.Fprintf(&b, "class:%s action:%s ", e.Class, e.Action)
+	if e.Direction != "" {
+		fmt.Fprintf(&b, "direction:%s ", e.Direction)
+	}
+	if e.Frame >= 0 {
+		fmt.Fprintf(&b, "frame:%d ", e.Frame)
+	}
+	if e.Part >= 0 {
+		fmt.Fprintf(&b, "part:%d ", e.Part)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors holds every problem found by a validation pass.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap allows errors.Is and errors.As to match any of the errors.
+func (errs ValidationErrors) Unwrap() []error {
+	wrapped := make([]error, len(errs))
+	for i, e := range errs {
+		wrapped[i] = e
+	}
+	return wrapped
+}
+
+// Validate checks the animation and its direction variants against the loaded tilesets.
+// It returns ValidationErrors listing every problem, or nil.
+func (a *Animation) Validate(resolver TilesetResolver) error {
+	errs := a.validate(resolver)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks every animation of the file against the loaded tilesets.
+// It returns ValidationErrors listing every problem, or nil.
+func (as *Animations) Validate(resolver TilesetResolver) error {
+	var errs ValidationErrors
+	for _, a := range as.Animations {
+		errs = append(errs, a.validate(resolver)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (a *Animation) validationError(frame int, part int, err error) *ValidationError {
+	return &ValidationError{
+		File:      a.Source,
+		Class:     a.Class,
+		Action:    a.Action,
+		Direction: a.Direction,
+		Frame:     frame,
+		Part:      part,
+		Err:       err,
+	}
+}
+
+func (a *Animation) validate(resolver TilesetResolver) ValidationErrors {
+	var errs ValidationErrors
+	report := func(frame int, part int, err error) {
+		errs = append(errs, a.validationError(frame, part, err))
+	}
+
+	tilesets := make(map[string]*tsx.Tileset, len(a.Tilesets))
+	lookup := func(name string) *tsx.Tileset {
+		if ts, ok := tilesets[name]; ok {
+			return ts
+		}
+		ts := resolver.GetTilesetByName(name)
+		tilesets[name] = ts
+		return ts
+	}
+
+	for _, name := range a.Tilesets {
+		// Variants share the tilesets of their animation, report them once
+		if lookup(name) == nil && a.Direction == "" {
+			report(-1, -1, fmt.Errorf("tileset:%s %w", name, ErrTilesetNotFound))
+		}
+	}
+
+	// Base animations of directional animations may have no frames of their own
+	if len(a.Frames) == 0 && len(a.Variants) == 0 {
+		report(-1, -1, ErrNoFrames)
+	}
+
+	for i, frame := range a.Frames {
+		if frame.Duration <= 0 {
+			report(i, -1, fmt.Errorf("duration:%d %w", frame.Duration, ErrInvalidDuration))
+		}
+
+		for j, part := range frame.Parts {
+			if part.TileID == -1 {
+				continue
+			}
+
+			names := a.Tilesets
+			if part.Tileset != "" {
+				names = []string{part.Tileset}
+				if lookup(part.Tileset) == nil && !slices.Contains(a.Tilesets, part.Tileset) {
+					report(i, j, fmt.Errorf("tileset:%s %w", part.Tileset, ErrTilesetNotFound))
+					continue
+				}
+			}
+
+			for _, name := range names {
+				ts := lookup(name)
+				if ts == nil {
+					// Already reported with the tilesets of the animation
+					continue
+				}
+				if part.TileID < 0 || part.TileID >= ts.TileCount {
+					report(i, j, fmt.Errorf("tileset:%s tile:%d %w", name, part.TileID, ErrTileNotFound))
+				}
+			}
+		}
+	}
+
+	for _, dir := range directions {
+		if v, ok := a.Variants[dir]; ok {
+			errs = append(errs, v.validate(resolver)...)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,207 @@
+package animation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/talvor/tiled/tsx"
+)
+
+const invalidAnimations = `
+animations:
+  - class: hero
+    action: attack
+    tilesets:
+      - hero
+    complex:
+      frames:
+        - duration: 100
+          parts:
+            - id: 1
+              tileset: 2
+            - id: 2
+              tileset: 3
+  - class: hero
+    action: spin
+    mode: sideways
+    tilesets:
+      - hero
+    simple:
+      duration: 100
+      frames: [1]
+  - class: hero
+    action: walk
+    tilesets:
+      - hero
+    directions:
+      sideways:
+        simple:
+          duration: 100
+          frames: [1]
+      left:
+        mirror: right
+`
+
+func TestDecodeReportsEveryProblem(t *testing.T) {
+	_, err := fileReader("hero.ani", strings.NewReader(invalidAnimations))
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+	if len(errs) != 5 {
+		t.Fatalf("got %d errors, want 5:\n%v", len(errs), err)
+	}
+	for _, target := range []error{ErrInvalidTilesetIndex, ErrUnknownMode, ErrUnknownDirection} {
+		if !errors.Is(err, target) {
+			t.Errorf("%v not reported:\n%v", target, err)
+		}
+	}
+	if errs[1].Part != 1 || errs[1].Action != "attack" {
+		t.Errorf("second part error: %+v", errs[1])
+	}
+}
+
+// tilesets resolves tilesets by name.
+type tilesets map[string]*tsx.Tileset
+
+func (t tilesets) GetTilesetByName(name string) *tsx.Tileset {
+	return t[name]
+}
+
+func TestValidate(t *testing.T) {
+	resolver := tilesets{
+		"hero":   {Name: "hero", TileCount: 4},
+		"weapon": {Name: "weapon", TileCount: 2},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   []error
+		// frame and part of the first error
+		frame, part int
+		direction   Direction
+	}{
+		{
+			name: "valid",
+			source: `
+    tilesets:
+      - hero
+    simple:
+      duration: 100
+      frames: [0, 3]`,
+		},
+		{
+			name: "missing tileset",
+			source: `
+    tilesets:
+      - hero
+      - cape
+    simple:
+      duration: 100
+      frames: [0]`,
+			want:  []error{ErrTilesetNotFound},
+			frame: -1, part: -1,
+		},
+		{
+			name: "tile out of range",
+			source: `
+    tilesets:
+      - hero
+    simple:
+      duration: 100
+      frames: [0, 4]`,
+			want:  []error{ErrTileNotFound},
+			frame: 1, part: 0,
+		},
+		{
+			name: "tile out of range of the part tileset",
+			source: `
+    tilesets:
+      - hero
+      - weapon
+    complex:
+      frames:
+        - duration: 100
+          parts:
+            - id: 3
+              tileset: 1
+            - id: 3
+              tileset: 2`,
+			want:  []error{ErrTileNotFound},
+			frame: 0, part: 1,
+		},
+		{
+			name: "no duration",
+			source: `
+    tilesets:
+      - hero
+    timed:
+      frames:
+        - id: 0
+          duration: 100
+        - id: 1
+          duration: 0`,
+			want:  []error{ErrInvalidDuration},
+			frame: 1, part: -1,
+		},
+		{
+			name: "variant",
+			source: `
+    tilesets:
+      - hero
+    directions:
+      right:
+        simple:
+          duration: 100
+          frames: [1, 7]
+      left:
+        mirror: right`,
+			want:      []error{ErrTileNotFound, ErrTileNotFound},
+			frame:     1,
+			part:      0,
+			direction: DirectionRight,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := loadAnimation(t, `
+animations:
+  - class: hero
+    action: walk`+tt.source+"\n")
+			err := a.Validate(resolver)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("got %v, want ValidationErrors", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if !errors.Is(errs[i], want) {
+					t.Errorf("error %d: got %v, want %v", i, errs[i], want)
+				}
+			}
+			if e := errs[0]; e.Frame != tt.frame || e.Part != tt.part || e.Direction != tt.direction || e.Class != "hero" || e.Action != "walk" {
+				t.Errorf("got %+v, want frame %d part %d direction %q", e, tt.frame, tt.part, tt.direction)
+			}
+		})
+	}
+}
+
+func TestValidateNoFrames(t *testing.T) {
+	a := &Animation{Class: "hero", Action: "walk", Tilesets: []string{"hero"}}
+	err := a.Validate(tilesets{"hero": {Name: "hero", TileCount: 4}})
+	if !errors.Is(err, ErrNoFrames) {
+		t.Errorf("got %v, want %v", err, ErrNoFrames)
+	}
+}
@@ -0,0 +1,121 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock reports how much game time has passed. Animations read the time from a Clock instead of
+// the wall clock so playback can be paused, slowed down or stepped deterministically.
+type Clock interface {
+	Now() time.Duration
+}
+
+// Default is the clock used by players and renderers that have not been given one.
+var Default Clock = NewReal()
+
+// Real follows the wall clock, scaled by its time scale and stopped while paused.
+type Real struct {
+	base      time.Duration
+	mark      time.Time
+	timeScale float64
+	paused    bool
+}
+
+func NewReal() *Real {
+	return &Real{
+		mark:      time.Now(),
+		timeScale: 1,
+	}
+}
+
+func (c *Real) Now() time.Duration {
+	if c.paused {
+		return c.base
+	}
+	return c.base + time.Duration(float64(time.Since(c.mark))*c.timeScale)
+}
+
+func (c *Real) Pause() {
+	if c.paused {
+		return
+	}
+	c.base = c.Now()
+	c.paused = true
+}
+
+func (c *Real) Resume() {
+	if !c.paused {
+		return
+	}
+	c.mark = time.Now()
+	c.paused = false
+}
+
+func (c *Real) Paused() bool {
+	return c.paused
+}
+
+func (c *Real) SetTimeScale(scale float64) {
+	c.base = c.Now()
+	c.mark = time.Now()
+	c.timeScale = scale
+}
+
+func (c *Real) TimeScale() float64 {
+	return c.timeScale
+}
+
+// Manual only moves when it is advanced, which makes playback deterministic.
+// Use Step from a fixed timestep game loop, eg. ebiten's Update, or Advance with a measured delta.
+type Manual struct {
+	// Timestep is the time added by Step
+	Timestep  time.Duration
+	now       time.Duration
+	timeScale float64
+	paused    bool
+}
+
+// NewManual returns a clock stepped by timestep, eg. time.Second / 60 for ebiten's default tick rate.
+func NewManual(timestep time.Duration) *Manual {
+	return &Manual{
+		Timestep:  timestep,
+		timeScale: 1,
+	}
+}
+
+func (c *Manual) Now() time.Duration {
+	return c.now
+}
+
+// Advance moves the clock forward by dt scaled by the time scale. It does nothing while paused.
+func (c *Manual) Advance(dt time.Duration) {
+	if c.paused {
+		return
+	}
+	c.now += time.Duration(float64(dt) * c.timeScale)
+}
+
+// Step advances the clock by a single timestep.
+func (c *Manual) Step() {
+	c.Advance(c.Timestep)
+}
+
+func (c *Manual) Pause() {
+	c.paused = true
+}
+
+func (c *Manual) Resume() {
+	c.paused = false
+}
+
+func (c *Manual) Paused() bool {
+	return c.paused
+}
+
+func (c *Manual) SetTimeScale(scale float64) {
+	c.timeScale = scale
+}
+
+func (c *Manual) TimeScale() float64 {
+	return c.timeScale
+}
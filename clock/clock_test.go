@@ -0,0 +1,75 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualStep(t *testing.T) {
+	c := NewManual(10 * time.Millisecond)
+	for range 100 {
+		c.Step()
+	}
+	if got := c.Now(); got != time.Second {
+		t.Errorf("100 steps: got %v, want %v", got, time.Second)
+	}
+}
+
+func TestManualPause(t *testing.T) {
+	c := NewManual(10 * time.Millisecond)
+	c.Step()
+	c.Pause()
+	c.Step()
+	c.Advance(time.Second)
+	if got := c.Now(); got != 10*time.Millisecond {
+		t.Errorf("paused clock moved to %v", got)
+	}
+
+	c.Resume()
+	c.Step()
+	if got := c.Now(); got != 20*time.Millisecond {
+		t.Errorf("resumed clock: got %v, want %v", got, 20*time.Millisecond)
+	}
+}
+
+func TestManualTimeScale(t *testing.T) {
+	c := NewManual(100 * time.Millisecond)
+	c.SetTimeScale(0.5)
+	c.Step()
+	c.SetTimeScale(2)
+	c.Step()
+	if got, want := c.Now(), 250*time.Millisecond; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRealPause(t *testing.T) {
+	c := NewReal()
+	c.Pause()
+	paused := c.Now()
+	time.Sleep(5 * time.Millisecond)
+	if got := c.Now(); got != paused {
+		t.Errorf("paused clock moved from %v to %v", paused, got)
+	}
+	if !c.Paused() {
+		t.Error("clock not reported as paused")
+	}
+
+	c.Resume()
+	time.Sleep(5 * time.Millisecond)
+	if c.Now() <= paused {
+		t.Error("resumed clock did not move")
+	}
+}
+
+func TestRealTimeScaleKeepsTime(t *testing.T) {
+	c := NewReal()
+	c.Pause()
+	before := c.Now()
+	c.SetTimeScale(0)
+	c.Resume()
+	time.Sleep(5 * time.Millisecond)
+	if got := c.Now(); got != before {
+		t.Errorf("clock with a zero time scale moved from %v to %v", before, got)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// encodeGIF writes the frames as an animated GIF, delays are in milliseconds.
+// Pixels less than half opaque become transparent as GIF has no partial transparency.
+func encodeGIF(w io.Writer, frames []*image.RGBA, delays []int, loops int) error {
+	pal, exact := framePalette(frames)
+
+	// GIF counts the repeats after the first play, 0 repeats forever and -1 plays once
+	anim := &gif.GIF{}
+	switch {
+	case loops == 1:
+		anim.LoopCount = -1
+	case loops > 1:
+		anim.LoopCount = loops - 1
+	}
+
+	for i, frame := range frames {
+		b := frame.Bounds()
+		img := image.NewPaletted(b, pal)
+		if !exact {
+			draw.FloydSteinberg.Draw(img, b, opaque(frame), b.Min)
+		} else {
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					img.SetColorIndex(x, y, uint8(pal.Index(gifColor(frame.RGBAAt(x, y)))))
+				}
+			}
+		}
+
+		anim.Image = append(anim.Image, img)
+		// GIF delays are in 100ths of a second
+		anim.Delay = append(anim.Delay, (delays[i]+5)/10)
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// framePalette returns the colors used by the frames with transparent at index 0. When the frames use
+// more than 255 colors the web safe palette is returned and exact is false.
+func framePalette(frames []*image.RGBA) (pal color.Palette, exact bool) {
+	pal = color.Palette{color.RGBA{}}
+	seen := map[color.RGBA]bool{{}: true}
+	for _, frame := range frames {
+		for i := 0; i < len(frame.Pix); i += 4 {
+			c := gifColor(color.RGBA{frame.Pix[i], frame.Pix[i+1], frame.Pix[i+2], frame.Pix[i+3]})
+			if seen[c] {
+				continue
+			}
+			seen[c] = true
+			pal = append(pal, c)
+			if len(pal) > 256 {
+				return append(color.Palette{color.RGBA{}}, palette.WebSafe...), false
+			}
+		}
+	}
+	return pal, true
+}
+
+// gifColor returns the opaque color of a pixel, or transparent for pixels less than half opaque.
+func gifColor(c color.RGBA) color.RGBA {
+	if c.A < 0x80 {
+		return color.RGBA{}
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return color.RGBA{n.R, n.G, n.B, 0xff}
+}
+
+// opaque returns a copy of the frame with the colors of gifColor.
+func opaque(frame *image.RGBA) *image.RGBA {
+	img := image.NewRGBA(frame.Bounds())
+	for i := 0; i < len(frame.Pix); i += 4 {
+		c := gifColor(color.RGBA{frame.Pix[i], frame.Pix[i+1], frame.Pix[i+2], frame.Pix[i+3]})
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// encodeAPNG writes the frames as an animated PNG, delays are in milliseconds.
+// Every frame replaces the whole canvas. Decoders without APNG support show the first frame.
+func encodeAPNG(w io.Writer, frames []*image.RGBA, delays []int, loops int) error {
+	pw := &pngWriter{w: w}
+	pw.write([]byte("\x89PNG\r\n\x1a\n"))
+
+	b := frames[0].Bounds()
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(b.Dx()))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(b.Dy()))
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 6 // truecolor with alpha
+	pw.chunk("IHDR", ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:], uint32(loops))
+	pw.chunk("acTL", actl)
+
+	var seq uint32
+	for i, frame := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		// The delay is the fraction delays[i]/1000 of a second
+		binary.BigEndian.PutUint16(fctl[20:], uint16(min(delays[i], 0xffff)))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		fctl[24] = 0 // dispose op none
+		fctl[25] = 0 // blend op source
+		pw.chunk("fcTL", fctl)
+		seq++
+
+		data, err := pngData(frame)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			pw.chunk("IDAT", data)
+			continue
+		}
+		fdat := make([]byte, 4, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, seq)
+		pw.chunk("fdAT", append(fdat, data...))
+		seq++
+	}
+
+	pw.chunk("IEND", nil)
+	return pw.err
+}
+
+// pngData returns the compressed scanlines of an 8 bit RGBA image.
+func pngData(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+
+	b := img.Bounds()
+	// Each scanline starts with its filter type, none
+	line := make([]byte, 1+4*b.Dx())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]
+		// PNG stores straight alpha
+		for i := 0; i < len(row); i += 4 {
+			n := color.NRGBAModel.Convert(color.RGBA{row[i], row[i+1], row[i+2], row[i+3]}).(color.NRGBA)
+			copy(line[1+i:], []byte{n.R, n.G, n.B, n.A})
+		}
+		if _, err := zw.Write(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pngWriter writes PNG chunks, keeping the first error.
+type pngWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (pw *pngWriter) write(p []byte) {
+	if pw.err == nil {
+		_, pw.err = pw.w.Write(p)
+	}
+}
+
+func (pw *pngWriter) chunk(name string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], name)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	pw.write(header)
+	pw.write(data)
+	pw.write(binary.BigEndian.AppendUint32(nil, crc.Sum32()))
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"slices"
+	"testing"
+)
+
+func TestEncodeGIFLoopCount(t *testing.T) {
+	tests := []struct {
+		loops int
+		want  int
+	}{
+		{0, 0},
+		{1, -1},
+		{3, 2},
+	}
+	frames := []*image.RGBA{image.NewRGBA(image.Rect(0, 0, 2, 2)), image.NewRGBA(image.Rect(0, 0, 2, 2))}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := encodeGIF(&buf, frames, []int{100, 100}, tt.loops); err != nil {
+			t.Fatal(err)
+		}
+		anim, err := gif.DecodeAll(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if anim.LoopCount != tt.want {
+			t.Errorf("%d loops: loop count %d, want %d", tt.loops, anim.LoopCount, tt.want)
+		}
+	}
+}
+
+// pngChunk is a chunk of a PNG file.
+type pngChunk struct {
+	name string
+	data []byte
+}
+
+// readChunks returns the chunks of a PNG file after the signature.
+func readChunks(t *testing.T, data []byte) []pngChunk {
+	t.Helper()
+	data = data[8:]
+	var chunks []pngChunk
+	for len(data) >= 12 {
+		n := binary.BigEndian.Uint32(data)
+		if len(data) < int(12+n) {
+			t.Fatalf("truncated %s chunk", data[4:8])
+		}
+		chunks = append(chunks, pngChunk{string(data[4:8]), data[8 : 8+n]})
+		data = data[12+n:]
+	}
+	return chunks
+}
+
+func TestEncodeAPNG(t *testing.T) {
+	frames := make([]*image.RGBA, 3)
+	for i := range frames {
+		frames[i] = image.NewRGBA(image.Rect(0, 0, 3, 2))
+		frames[i].Set(i, 0, color.RGBA{R: 0xff, A: 0xff})
+	}
+	// Colors are premultiplied in the frames and straight in the file
+	frames[0].Set(2, 1, color.RGBA{G: 0x40, A: 0x80})
+
+	for _, loops := range []int{0, 1, 3} {
+		var buf bytes.Buffer
+		if err := encodeAPNG(&buf, frames, []int{100, 150, 200}, loops); err != nil {
+			t.Fatal(err)
+		}
+
+		// Decoders without APNG support show the first frame
+		img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds() != frames[0].Bounds() {
+			t.Fatalf("bounds %v, want %v", img.Bounds(), frames[0].Bounds())
+		}
+		for y := range 2 {
+			for x := range 3 {
+				got := color.NRGBAModel.Convert(img.At(x, y))
+				if want := color.NRGBAModel.Convert(frames[0].At(x, y)); got != want {
+					t.Errorf("pixel %d,%d: got %v, want %v", x, y, got, want)
+				}
+			}
+		}
+
+		var actl []byte
+		var names []string
+		var delays []uint16
+		for _, c := range readChunks(t, buf.Bytes()) {
+			names = append(names, c.name)
+			switch c.name {
+			case "acTL":
+				actl = c.data
+			case "fcTL":
+				delays = append(delays, binary.BigEndian.Uint16(c.data[20:]))
+			}
+		}
+		if len(actl) != 8 {
+			t.Fatalf("%d loops: acTL %v", loops, actl)
+		}
+		if got := binary.BigEndian.Uint32(actl); got != uint32(len(frames)) {
+			t.Errorf("%d loops: acTL frames %d, want %d", loops, got, len(frames))
+		}
+		if got := binary.BigEndian.Uint32(actl[4:]); got != uint32(loops) {
+			t.Errorf("%d loops: acTL plays %d, want %d", loops, got, loops)
+		}
+
+		want := []string{"IHDR", "acTL", "fcTL", "IDAT", "fcTL", "fdAT", "fcTL", "fdAT", "IEND"}
+		if !slices.Equal(names, want) {
+			t.Errorf("chunks %v, want %v", names, want)
+		}
+		if !slices.Equal(delays, []uint16{100, 150, 200}) {
+			t.Errorf("delays %v, want [100 150 200]", delays)
+		}
+	}
+}
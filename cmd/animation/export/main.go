@@ -0,0 +1,226 @@
+// Command export renders an animation to an animated GIF or APNG, eg. to preview it without running a game.
+//
+//	go run ./cmd/animation/export -animations ./animations -tilesets ./sprites -o walk.gif -scale 4 player walking
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/talvor/tiled/animation"
+	anim "github.com/talvor/tiled/animation/manager"
+	"github.com/talvor/tiled/render"
+	"github.com/talvor/tiled/tmx"
+	"github.com/talvor/tiled/tsx"
+	tsxm "github.com/talvor/tiled/tsx/manager"
+)
+
+// Colors of the collider overlay
+var (
+	colliderColor     = color.NRGBA{R: 0xff, A: 0xff}
+	tileColliderColor = color.NRGBA{G: 0xff, B: 0xff, A: 0xff}
+)
+
+func main() {
+	var (
+		output     = flag.String("o", "", "output file, .gif or .png for an APNG, defaults to class_action.gif")
+		animations = flag.String("animations", ".", "comma separated directories of animations")
+		tilesets   = flag.String("tilesets", ".", "comma separated directories of tilesets")
+		direction  = flag.String("direction", "", "variant of a directional animation, eg. up_left, the default variant when empty")
+		scale      = flag.Int("scale", 1, "integer scale of the image")
+		background = flag.String("background", "", "background color as #rrggbb, transparent when empty")
+		colliders  = flag.Bool("colliders", false, "draw the colliders of the animation and its tiles")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] class action\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 || *scale < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	class, action := flag.Arg(0), flag.Arg(1)
+
+	if *output == "" {
+		*output = class + "_" + action + ".gif"
+	}
+	var bg color.NRGBA
+	if *background != "" {
+		var err error
+		if bg, err = tmx.ParseColor(*background); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	tm := tsxm.NewManager(strings.Split(*tilesets, ","))
+	am := anim.NewManager(strings.Split(*animations, ","))
+	for _, ts := range am.Tilesets {
+		tm.RegisterTileset(ts)
+	}
+
+	ani, err := am.GetDirectionalAnimation(class, action, animation.Direction(*direction))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *direction == "" {
+		// Animations with only directions export their default variant, like players play it
+		ani = ani.DefaultVariant()
+	}
+	if err := ani.Validate(tm); err != nil {
+		log.Fatal(err)
+	}
+
+	sequence := playback(ani)
+	bounds := animationBounds(ani, tm, *colliders)
+	if bounds.Empty() {
+		log.Fatalf("class:%s action:%s has nothing to draw", class, action)
+	}
+
+	frames := make([]*image.RGBA, len(sequence))
+	delays := make([]int, len(sequence))
+	for i, idx := range sequence {
+		canvas := render.NewSoftware(bounds.Dx()**scale, bounds.Dy()**scale)
+		if bg.A != 0 {
+			canvas.Clear(bg)
+		}
+		r := render.NewRenderer(canvas, tm)
+
+		op := &render.Options{}
+		op.GeoM.Translate(-float64(bounds.Min.X), -float64(bounds.Min.Y))
+		op.GeoM.Scale(float64(*scale), float64(*scale))
+
+		frame := ani.Frames[idx]
+		if err := r.DrawFrame(ani, frame, op); err != nil {
+			log.Fatal(err)
+		}
+		if *colliders {
+			drawColliders(canvas, ani, frame, tm, op)
+		}
+
+		frames[i] = canvas.Image()
+		delays[i] = frame.Duration
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	loops := loopCount(ani)
+	switch strings.ToLower(filepath.Ext(*output)) {
+	case ".gif":
+		err = encodeGIF(f, frames, delays, loops)
+	case ".png", ".apng":
+		err = encodeAPNG(f, frames, delays, loops)
+	default:
+		err = fmt.Errorf("file: %s must be .gif or .png", *output)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// playback returns the frame indexes of one cycle of the animation in the order its mode plays them.
+func playback(ani *animation.Animation) []int {
+	count := len(ani.Frames)
+	sequence := make([]int, 0, 2*count)
+	for i := range count {
+		sequence = append(sequence, i)
+	}
+	if ani.Mode == animation.ModePingPong {
+		for i := count - 2; i > 0; i-- {
+			sequence = append(sequence, i)
+		}
+	}
+	return sequence
+}
+
+// loopCount returns how often the cycle plays, 0 repeats it forever.
+func loopCount(ani *animation.Animation) int {
+	switch ani.Mode {
+	case animation.ModeOnce, animation.ModeHold:
+		return 1
+	}
+	return ani.LoopCount
+}
+
+// partTiles calls fn with the tileset of every tile drawn for the part.
+func partTiles(ani *animation.Animation, part animation.Part, tilesets render.TilesetResolver, fn func(ts *tsx.Tileset)) {
+	names := ani.Tilesets
+	if part.Tileset != "" {
+		names = []string{part.Tileset}
+	}
+	for _, name := range names {
+		if ts := tilesets.GetTilesetByName(name); ts != nil {
+			fn(ts)
+		}
+	}
+}
+
+// animationBounds returns the area covered by every frame of the animation relative to its anchor.
+func animationBounds(ani *animation.Animation, tilesets render.TilesetResolver, colliders bool) image.Rectangle {
+	var bounds image.Rectangle
+	for _, frame := range ani.Frames {
+		for _, part := range frame.Parts {
+			if part.TileID == -1 {
+				continue
+			}
+			partTiles(ani, part, tilesets, func(ts *tsx.Tileset) {
+				min := ani.PartPosition(part, ts.TileWidth)
+				bounds = bounds.Union(image.Rectangle{Min: min, Max: min.Add(image.Pt(ts.TileWidth, ts.TileHeight))})
+			})
+		}
+	}
+	if colliders {
+		for _, rect := range ani.Colliders {
+			bounds = bounds.Union(rect.Sub(ani.Anchor))
+		}
+	}
+	return bounds
+}
+
+// drawColliders outlines the colliders of the animation and the collision objects of the tiles of the frame.
+func drawColliders(canvas *render.Software, ani *animation.Animation, frame animation.Frame, tilesets render.TilesetResolver, op *render.Options) {
+	outline := func(rect image.Rectangle, c color.Color) {
+		x0, y0 := op.GeoM.Apply(float64(rect.Min.X), float64(rect.Min.Y))
+		x1, y1 := op.GeoM.Apply(float64(rect.Max.X), float64(rect.Max.Y))
+		canvas.DrawRect(image.Rect(int(x0), int(y0), int(x1), int(y1)), c)
+	}
+
+	for _, part := range frame.Parts {
+		if part.TileID == -1 {
+			continue
+		}
+		partTiles(ani, part, tilesets, func(ts *tsx.Tileset) {
+			tile, err := ts.GetTileByID(uint32(part.TileID))
+			if err != nil {
+				return
+			}
+			origin := ani.PartPosition(part, ts.TileWidth)
+			for _, og := range tile.ObjectGroups {
+				for _, o := range og.Objects {
+					rect := image.Rect(int(o.X), int(o.Y), int(o.X+o.Width), int(o.Y+o.Height))
+					if part.FlipHorizontal {
+						rect.Min.X, rect.Max.X = ts.TileWidth-rect.Max.X, ts.TileWidth-rect.Min.X
+					}
+					if part.FlipVertical {
+						rect.Min.Y, rect.Max.Y = ts.TileHeight-rect.Max.Y, ts.TileHeight-rect.Min.Y
+					}
+					outline(rect.Add(origin), tileColliderColor)
+				}
+			}
+		})
+	}
+
+	for _, rect := range ani.Colliders {
+		outline(rect.Sub(ani.Anchor), colliderColor)
+	}
+}
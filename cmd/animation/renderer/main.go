@@ -8,6 +8,7 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/talvor/tiled/animation"
 	anim "github.com/talvor/tiled/animation/manager"
 	"github.com/talvor/tiled/animation/renderer"
 	"github.com/talvor/tiled/common"
@@ -15,7 +16,14 @@ import (
 	tsxr "github.com/talvor/tiled/tsx/renderer"
 )
 
-var anir *renderer.Renderer
+var (
+	anir          *renderer.Renderer
+	playerWalk    *animation.AnimationPlayer
+	enemyRun      *animation.AnimationPlayer
+	timedIdle     *animation.AnimationPlayer
+	complexChop   *animation.AnimationPlayer
+	secondEnemies []*animation.AnimationPlayer
+)
 
 func init() {
 	homeDir, _ := os.UserHomeDir()
@@ -29,6 +37,23 @@ func init() {
 	anir = renderer.NewRenderer(anim, tsxr)
 
 	anir.SetTilesetResolver(tsxr.TilesetManager)
+
+	playerWalk = mustPlayer(anim.NewPlayer("simple_player", "walking"))
+	enemyRun = mustPlayer(anim.NewPlayer("simple_enemy", "running"))
+	timedIdle = mustPlayer(anim.NewPlayer("timed_player", "idle"))
+	complexChop = mustPlayer(anim.NewPlayer("complex_player", "chop"))
+
+	// Each enemy owns a player so they animate independently
+	for i := range 3 {
+		p := mustPlayer(anim.NewPlayer("simple_enemy", "running"))
+		p.Speed = 1 + float64(i)*0.5
+		secondEnemies = append(secondEnemies, p)
+	}
+}
+
+func mustPlayer(p *animation.AnimationPlayer, err error) *animation.AnimationPlayer {
+	panicOnError(err)
+	return p
 }
 
 type Game struct{}
@@ -65,21 +90,21 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	cx := float64(collider.Min.X) + dx
 	cy := float64(collider.Min.Y) + dy
 	ebitenutil.DrawRect(screen, cx, cy, float64(collider.Dx()), float64(collider.Dy()), color.RGBA{255, 0, 0, 25})
-	panicOnError(anir.Draw("simple_player", "walking", &common.DrawOptions{
+	panicOnError(anir.Draw(playerWalk, &common.DrawOptions{
 		Screen: screen,
 		Op:     op,
 	}))
 
 	moveRight(48, 0)
 
-	panicOnError(anir.Draw("simple_enemy", "running", &common.DrawOptions{
+	panicOnError(anir.Draw(enemyRun, &common.DrawOptions{
 		Screen: screen,
 		Op:     op,
 	}))
 
 	moveRight(48, 0)
 
-	panicOnError(anir.Draw("timed_player", "idle", &common.DrawOptions{
+	panicOnError(anir.Draw(timedIdle, &common.DrawOptions{
 		Screen: screen,
 		Op:     op,
 	}))
@@ -90,7 +115,7 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	cx = float64(collider.Min.X) + dx
 	cy = float64(collider.Min.Y) + dy - 8
 	ebitenutil.DrawRect(screen, cx, cy, float64(collider.Dx()), float64(collider.Dy()), color.RGBA{255, 0, 0, 25})
-	panicOnError(anir.Draw("complex_player", "chop", &common.DrawOptions{
+	panicOnError(anir.Draw(complexChop, &common.DrawOptions{
 		Screen: screen,
 		Op:     op,
 	}))
@@ -98,6 +123,14 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	moveRight(48, 0)
 
 	nextLine(48)
+
+	for _, p := range secondEnemies {
+		panicOnError(anir.Draw(p, &common.DrawOptions{
+			Screen: screen,
+			Op:     op,
+		}))
+		moveRight(48, 0)
+	}
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
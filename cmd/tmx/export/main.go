@@ -0,0 +1,236 @@
+// Command export renders a map, or a selection of its layers or tiles, to a PNG file.
+//
+//	go run ./cmd/tmx/export -o town.png -scale 2 -objects -grid maps/town.tmx
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/talvor/tiled/render"
+	"github.com/talvor/tiled/tmx"
+	"github.com/talvor/tiled/tsx/manager"
+)
+
+func main() {
+	var (
+		output   = flag.String("o", "map.png", "output PNG file")
+		layers   = flag.String("layers", "", "comma separated layers or groups to draw even when hidden in the map, all visible layers when empty")
+		rect     = flag.String("rect", "", "tile rectangle to draw as x,y,width,height, the whole map when empty")
+		scale    = flag.Float64("scale", 1, "scale of the image")
+		objects  = flag.Bool("objects", false, "draw the outlines of objects")
+		grid     = flag.Bool("grid", false, "draw tile grid lines")
+		tilesets = flag.String("tilesets", "", "comma separated directories of tilesets, the map tilesets are always loaded")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] map.tmx\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *scale <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	m, err := tmx.LoadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var dirs []string
+	if *tilesets != "" {
+		dirs = strings.Split(*tilesets, ",")
+	}
+	tm := manager.NewManager(dirs)
+	for _, ts := range m.Tilesets {
+		if ts.Source == "" || tm.HasTilesetBySource(ts.Source) {
+			continue
+		}
+		if _, err := tm.AddTileset(ts.Source); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	area := image.Rect(0, 0, m.Width, m.Height)
+	if *rect != "" {
+		if area, err = parseRect(*rect); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// The map is drawn unscaled, the geometry of the options applies to each tile before it is placed
+	mapCanvas := render.NewSoftware(area.Dx()*m.TileWidth, area.Dy()*m.TileHeight)
+	r := render.NewRenderer(mapCanvas, tm)
+	mapOp := &render.Options{}
+	mapOp.GeoM.Translate(-float64(area.Min.X*m.TileWidth), -float64(area.Min.Y*m.TileHeight))
+
+	var selection []string
+	if *layers != "" {
+		for _, name := range strings.Split(*layers, ",") {
+			selection = append(selection, strings.TrimSpace(name))
+		}
+	}
+
+	if len(selection) == 0 {
+		err = r.DrawMap(m, mapOp)
+	} else {
+		for _, name := range selection {
+			if err = showLayer(m, r.LayerOverrides, name); err != nil {
+				break
+			}
+			if err = r.DrawMapLayer(m, name, mapOp); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Map pixels to image pixels
+	op := &render.Options{}
+	op.GeoM.Translate(-float64(area.Min.X*m.TileWidth), -float64(area.Min.Y*m.TileHeight))
+	op.GeoM.Scale(*scale, *scale)
+
+	width := int(float64(area.Dx()*m.TileWidth) * *scale)
+	height := int(float64(area.Dy()*m.TileHeight) * *scale)
+	canvas := render.NewSoftware(width, height)
+	scaleOp := &render.Options{}
+	scaleOp.GeoM.Scale(*scale, *scale)
+	if err := canvas.DrawImage(mapCanvas.Image(), mapCanvas.Image().Bounds(), scaleOp); err != nil {
+		log.Fatal(err)
+	}
+
+	if *grid {
+		drawGrid(canvas, m, area, *scale)
+	}
+	if *objects {
+		if err := drawObjects(canvas, m, r.LayerOverrides, selection, op); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, canvas.Image()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// showLayer makes the named layer and the groups containing it visible, even when hidden in the map.
+// Layers inside a selected group keep their visibility.
+func showLayer(m *tmx.Map, overrides tmx.LayerOverrides, name string) error {
+	n, err := m.FindLayer(name)
+	if err != nil {
+		return err
+	}
+
+	overrides.SetVisible(name, true)
+	for g := n.Base().Parent; g != nil; g = g.Parent {
+		overrides.SetVisible(g.Name, true)
+	}
+	return nil
+}
+
+// selected reports whether the layer or a group containing it is in the selection, every layer is
+// selected by an empty selection.
+func selected(b *tmx.LayerBase, selection []string) bool {
+	if len(selection) == 0 {
+		return true
+	}
+	if slices.Contains(selection, b.Name) {
+		return true
+	}
+	return b.Parent != nil && selected(&b.Parent.LayerBase, selection)
+}
+
+func parseRect(s string) (image.Rectangle, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return image.Rectangle{}, fmt.Errorf("rect: %q must be x,y,width,height", s)
+	}
+
+	var v [4]int
+	for i, f := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("rect: %q %w", s, err)
+		}
+		v[i] = n
+	}
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3]), nil
+}
+
+func drawGrid(canvas *render.Software, m *tmx.Map, area image.Rectangle, scale float64) {
+	c := color.NRGBA{A: 0x40}
+	b := canvas.Image().Bounds()
+
+	for x := 0; x <= area.Dx(); x++ {
+		px := min(float64(x*m.TileWidth)*scale, float64(b.Max.X-1))
+		canvas.DrawLine(px, 0, px, float64(b.Max.Y-1), c)
+	}
+	for y := 0; y <= area.Dy(); y++ {
+		py := min(float64(y*m.TileHeight)*scale, float64(b.Max.Y-1))
+		canvas.DrawLine(0, py, float64(b.Max.X-1), py, c)
+	}
+}
+
+// drawObjects draws the outline of the visible objects of the selected layers in the color of their group.
+func drawObjects(canvas *render.Software, m *tmx.Map, overrides tmx.LayerOverrides, selection []string, op *render.Options) error {
+	for i := range m.ObjectGroups {
+		og := &m.ObjectGroups[i]
+		if !og.Style(overrides).Visible || !selected(&og.LayerBase, selection) {
+			continue
+		}
+
+		offsetX, offsetY := og.Offset()
+		c, err := tmx.ParseColor(og.Color)
+		if err != nil {
+			return err
+		}
+		if og.Color == "" {
+			// Default object color of Tiled
+			c = color.NRGBA{R: 0xa0, G: 0xa0, B: 0xa4, A: 0xff}
+		}
+
+		for j := range og.Objects {
+			o, err := m.ResolveTemplate(&og.Objects[j])
+			if err != nil {
+				return err
+			}
+			if !o.Visible {
+				continue
+			}
+
+			outline := o.Outline()
+			points := make([][2]float64, len(outline))
+			for k, p := range outline {
+				x, y := op.GeoM.Apply(p[0]+float64(offsetX), p[1]+float64(offsetY))
+				points[k] = [2]float64{x, y}
+			}
+
+			isPoint := o.Width == 0 && o.Height == 0 && len(o.Polygons) == 0 && len(o.PolyLines) == 0
+			if isPoint {
+				// Point objects are drawn as a cross
+				x, y := points[0][0], points[0][1]
+				canvas.DrawLine(x-2, y, x+2, y, c)
+				canvas.DrawLine(x, y-2, x, y+2, c)
+				continue
+			}
+			canvas.DrawPath(points, len(o.PolyLines) == 0, c)
+		}
+	}
+	return nil
+}
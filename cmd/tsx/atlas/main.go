@@ -0,0 +1,140 @@
+// Command atlas packs the tilesets found in directories into atlas pages, writing the pages and the
+// metadata used by the renderer. Placing the .atlas file in a tileset directory makes the renderer use it.
+//
+//	go run ./cmd/tsx/atlas -o sprites/sprites.atlas -padding 2 -extrude 1 sprites
+//	go run ./cmd/tsx/atlas -o maps/town.atlas -maps maps -extrude 1 tilesets
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/talvor/tiled/tmx"
+	tmxm "github.com/talvor/tiled/tmx/manager"
+	"github.com/talvor/tiled/tsx"
+	"github.com/talvor/tiled/tsx/atlas"
+	tsxm "github.com/talvor/tiled/tsx/manager"
+)
+
+func main() {
+	var (
+		output   = flag.String("o", "tiles.atlas", "output atlas file, pages are written next to it")
+		names    = flag.String("names", "", "comma separated tilesets to pack, all tilesets when empty")
+		mapDirs  = flag.String("maps", "", "comma separated directories of maps, only the tiles used by the maps are packed")
+		padding  = flag.Int("padding", 2, "transparent pixels between tiles")
+		extrude  = flag.Int("extrude", 1, "pixels the border of each tile is repeated outwards")
+		pageSize = flag.Int("size", atlas.DefaultPageSize, "maximum width and height of a page")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] tileset-dir...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || *padding < 0 || *extrude < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	tm := tsxm.NewManager(flag.Args())
+
+	opts := atlas.Options{
+		MaxWidth:  *pageSize,
+		MaxHeight: *pageSize,
+		Padding:   *padding,
+		Extrude:   *extrude,
+	}
+	if *mapDirs != "" {
+		mm := tmxm.NewManager(strings.Split(*mapDirs, ","))
+		tiles, err := usedTiles(mm, tm)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.Tiles = tiles
+	}
+
+	var tilesets []*tsx.Tileset
+	if *names != "" {
+		for _, name := range strings.Split(*names, ",") {
+			ts := tm.GetTilesetByName(strings.TrimSpace(name))
+			if ts == nil {
+				log.Fatalf("tileset: %s %v", name, tsxm.ErrTilesetNotFound)
+			}
+			tilesets = append(tilesets, ts)
+		}
+	} else {
+		for _, ts := range tm.TilesetsByName {
+			// Tilesets not used by any map are left out
+			if opts.Tiles != nil && opts.Tiles[ts.Name] == nil {
+				continue
+			}
+			tilesets = append(tilesets, ts)
+		}
+	}
+	slices.SortFunc(tilesets, func(a, b *tsx.Tileset) int { return strings.Compare(a.Name, b.Name) })
+
+	a, err := atlas.Pack(tilesets, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := a.Save(*output); err != nil {
+		log.Fatal(err)
+	}
+
+	var count int
+	for _, ts := range a.Tilesets {
+		count += len(ts.Tiles)
+	}
+	log.Printf("packed %d tiles of %d tilesets into %d pages", count, len(a.Tilesets), len(a.Pages))
+}
+
+// usedTiles returns the tiles used by the layers and tile objects of the maps, by tileset name.
+// Tilesets of the maps missing from the manager are loaded from their source.
+func usedTiles(mm *tmxm.MapManager, tm *tsxm.TilesetManager) (map[string][]uint32, error) {
+	used := make(map[string]map[uint32]bool)
+	add := func(m *tmx.Map, gid tmx.GID) error {
+		mts, id := m.DecodeTileGID(gid)
+		if mts == nil {
+			return nil
+		}
+		ts := tm.GetTilesetBySource(mts.Source)
+		if ts == nil {
+			var err error
+			if ts, err = tm.AddTileset(mts.Source); err != nil {
+				return err
+			}
+		}
+		if used[ts.Name] == nil {
+			used[ts.Name] = make(map[uint32]bool)
+		}
+		used[ts.Name][uint32(id)] = true
+		return nil
+	}
+
+	for _, m := range mm.Maps {
+		for _, l := range m.Layers {
+			for _, gid := range l.Tiles {
+				if err := add(m, gid); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, og := range m.ObjectGroups {
+			for _, o := range og.Objects {
+				if err := add(m, tmx.GID(o.GID)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	tiles := make(map[string][]uint32, len(used))
+	for name, ids := range used {
+		tiles[name] = slices.Sorted(maps.Keys(ids))
+	}
+	return tiles, nil
+}
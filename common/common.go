@@ -1,16 +1,13 @@
 package common
 
 import (
-	"errors"
-	"fmt"
-	"os"
-
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/talvor/tiled/internal/pathutil"
 )
 
 var (
-	ErrPathNotFound     = errors.New("path not found")
-	ErrPathNotDirectory = errors.New("path is not a directory")
+	ErrPathNotFound     = pathutil.ErrPathNotFound
+	ErrPathNotDirectory = pathutil.ErrPathNotDirectory
 )
 
 type DrawOptions struct {
@@ -18,19 +15,12 @@ type DrawOptions struct {
 	Op             *ebiten.DrawImageOptions
 	FlipHorizontal bool
 	FlipVertical   bool
-	OffsetX        float64
-	OffsetY        float64
+	// FlipDiagonal swaps the axes of the tile before the other flips, see tmx.GIDDiagonalFlip
+	FlipDiagonal bool
+	OffsetX      float64
+	OffsetY      float64
 }
 
 func PathShouldBeDirectory(path string) error {
-	info, err := os.Stat(path)
-	// Check if the path exists
-	if os.IsNotExist(err) {
-		return fmt.Errorf("path: %s %w", path, ErrPathNotFound)
-	}
-	// Check if the path is a directory
-	if !info.IsDir() {
-		return fmt.Errorf("path: %s %w", path, ErrPathNotDirectory)
-	}
-	return nil
+	return pathutil.PathShouldBeDirectory(path)
 }
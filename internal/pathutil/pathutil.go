@@ -0,0 +1,26 @@
+// Package pathutil holds path helpers shared by the managers without depending on ebiten.
+package pathutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	ErrPathNotFound     = errors.New("path not found")
+	ErrPathNotDirectory = errors.New("path is not a directory")
+)
+
+func PathShouldBeDirectory(path string) error {
+	info, err := os.Stat(path)
+	// Check if the path exists
+	if os.IsNotExist(err) {
+		return fmt.Errorf("path: %s %w", path, ErrPathNotFound)
+	}
+	// Check if the path is a directory
+	if !info.IsDir() {
+		return fmt.Errorf("path: %s %w", path, ErrPathNotDirectory)
+	}
+	return nil
+}
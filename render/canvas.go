@@ -0,0 +1,91 @@
+// Package render draws tiles, map layers and animation frames through a backend agnostic Canvas.
+// Software is a pure Go canvas drawing into an image.RGBA, eg. for snapshot tests and thumbnails,
+// tsx/renderer provides a canvas drawing to an ebiten image.
+package render
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/talvor/tiled/tsx"
+)
+
+// Image is an image loaded by a canvas.
+type Image interface {
+	Bounds() image.Rectangle
+}
+
+// Canvas is a drawing target.
+type Canvas interface {
+	// LoadImage loads an image file for drawing on the canvas
+	LoadImage(source string) (Image, error)
+	// DrawImage draws the region src of an image loaded by the canvas, other images return
+	// ErrUnsupportedImage
+	DrawImage(img Image, src image.Rectangle, op *Options) error
+}
+
+// TileCanvas is a canvas drawing tiles itself, eg. from texture atlases or with the playback of animated
+// tiles changed. The renderer draws tiles through it instead of loading the tileset image.
+type TileCanvas interface {
+	Canvas
+	// DrawTile draws a tile of the tileset, animated tiles draw their current frame
+	DrawTile(ts *tsx.Tileset, tileID uint32, op *Options) error
+}
+
+// Options control how an image is drawn.
+type Options struct {
+	// GeoM transforms the image after it has been flipped
+	GeoM GeoM
+	// ColorScale multiplies the premultiplied colors of the image, eg. for tinting and opacity
+	ColorScale     ColorScale
+	FlipHorizontal bool
+	FlipVertical   bool
+	// FlipDiagonal swaps the axes of the image before the other flips
+	FlipDiagonal bool
+}
+
+// Transform returns the transform of a w x h image with the flips applied around its centre.
+func (op *Options) Transform(w, h int) GeoM {
+	g := flipGeoM(float64(w), float64(h), op.FlipHorizontal, op.FlipVertical, op.FlipDiagonal)
+	g.Concat(op.GeoM)
+	return g
+}
+
+// ColorScale multiplies premultiplied colors, the zero value leaves them unchanged.
+// It mirrors ebiten.ColorScale.
+type ColorScale struct {
+	r_1, g_1, b_1, a_1 float32
+}
+
+func (c *ColorScale) Reset() {
+	*c = ColorScale{}
+}
+
+func (c *ColorScale) R() float32 { return c.r_1 + 1 }
+func (c *ColorScale) G() float32 { return c.g_1 + 1 }
+func (c *ColorScale) B() float32 { return c.b_1 + 1 }
+func (c *ColorScale) A() float32 { return c.a_1 + 1 }
+
+func (c *ColorScale) Scale(r, g, b, a float32) {
+	c.r_1 = (c.r_1+1)*r - 1
+	c.g_1 = (c.g_1+1)*g - 1
+	c.b_1 = (c.b_1+1)*b - 1
+	c.a_1 = (c.a_1+1)*a - 1
+}
+
+// ScaleAlpha changes the opacity, colors are premultiplied so every channel is scaled.
+func (c *ColorScale) ScaleAlpha(a float32) {
+	c.Scale(a, a, a, a)
+}
+
+// ScaleWithColor multiplies the colors by clr.
+func (c *ColorScale) ScaleWithColor(clr color.Color) {
+	r, g, b, a := clr.RGBA()
+	c.Scale(float32(r)/0xffff, float32(g)/0xffff, float32(b)/0xffff, float32(a)/0xffff)
+}
+
+// Tint multiplies the color channels by clr, leaving the opacity unchanged.
+func (c *ColorScale) Tint(clr color.Color) {
+	n := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	c.Scale(float32(n.R)/0xff, float32(n.G)/0xff, float32(n.B)/0xff, 1)
+}
@@ -0,0 +1,150 @@
+package render
+
+import "math"
+
+// GeoM is an affine transform, the zero value is the identity. It mirrors ebiten.GeoM so
+// transforms can be shared between backends.
+//
+//	| a b tx |
+//	| c d ty |
+type GeoM struct {
+	a_1 float64 // a - 1, so that the zero value is the identity
+	b   float64
+	c   float64
+	d_1 float64 // d - 1
+	tx  float64
+	ty  float64
+}
+
+func (g *GeoM) Reset() {
+	*g = GeoM{}
+}
+
+// Element returns the value of the matrix at row i and column j.
+func (g *GeoM) Element(i, j int) float64 {
+	switch {
+	case i == 0 && j == 0:
+		return g.a_1 + 1
+	case i == 0 && j == 1:
+		return g.b
+	case i == 0 && j == 2:
+		return g.tx
+	case i == 1 && j == 0:
+		return g.c
+	case i == 1 && j == 1:
+		return g.d_1 + 1
+	case i == 1 && j == 2:
+		return g.ty
+	}
+	panic("render: index out of range")
+}
+
+// SetElement sets the value of the matrix at row i and column j.
+func (g *GeoM) SetElement(i, j int, v float64) {
+	switch {
+	case i == 0 && j == 0:
+		g.a_1 = v - 1
+	case i == 0 && j == 1:
+		g.b = v
+	case i == 0 && j == 2:
+		g.tx = v
+	case i == 1 && j == 0:
+		g.c = v
+	case i == 1 && j == 1:
+		g.d_1 = v - 1
+	case i == 1 && j == 2:
+		g.ty = v
+	default:
+		panic("render: index out of range")
+	}
+}
+
+// Apply transforms the point (x, y).
+func (g *GeoM) Apply(x, y float64) (float64, float64) {
+	return (g.a_1+1)*x + g.b*y + g.tx, g.c*x + (g.d_1+1)*y + g.ty
+}
+
+// Concat applies other after the current transform.
+func (g *GeoM) Concat(other GeoM) {
+	a, b, c, d := g.a_1+1, g.b, g.c, g.d_1+1
+	oa, ob, oc, od := other.a_1+1, other.b, other.c, other.d_1+1
+
+	g.a_1 = oa*a + ob*c - 1
+	g.b = oa*b + ob*d
+	g.c = oc*a + od*c
+	g.d_1 = oc*b + od*d - 1
+	g.tx, g.ty = oa*g.tx+ob*g.ty+other.tx, oc*g.tx+od*g.ty+other.ty
+}
+
+func (g *GeoM) Translate(tx, ty float64) {
+	g.tx += tx
+	g.ty += ty
+}
+
+func (g *GeoM) Scale(x, y float64) {
+	var s GeoM
+	s.a_1 = x - 1
+	s.d_1 = y - 1
+	g.Concat(s)
+}
+
+// Rotate rotates the transform clockwise by theta radians, y growing downwards.
+func (g *GeoM) Rotate(theta float64) {
+	if theta == 0 {
+		return
+	}
+	sin, cos := math.Sincos(theta)
+	var r GeoM
+	r.a_1 = cos - 1
+	r.b = -sin
+	r.c = sin
+	r.d_1 = cos - 1
+	g.Concat(r)
+}
+
+// Invert inverts the transform, it reports false when the transform can't be inverted.
+func (g *GeoM) Invert() bool {
+	a, b, c, d := g.a_1+1, g.b, g.c, g.d_1+1
+	det := a*d - b*c
+	if det == 0 {
+		return false
+	}
+
+	ia, ib, ic, id := d/det, -b/det, -c/det, a/det
+	tx, ty := g.tx, g.ty
+	g.a_1 = ia - 1
+	g.b = ib
+	g.c = ic
+	g.d_1 = id - 1
+	g.tx = -(ia*tx + ib*ty)
+	g.ty = -(ic*tx + id*ty)
+	return true
+}
+
+// flipGeoM returns the transform of a w x h image flipped around its centre. Diagonal flips swap the
+// axes first, like flipped tiles in maps.
+func flipGeoM(w, h float64, horizontal, vertical, diagonal bool) GeoM {
+	var g GeoM
+	if !horizontal && !vertical && !diagonal {
+		return g
+	}
+
+	g.Translate(-w/2, -h/2)
+	if diagonal {
+		var transpose GeoM
+		transpose.SetElement(0, 0, 0)
+		transpose.SetElement(0, 1, 1)
+		transpose.SetElement(1, 0, 1)
+		transpose.SetElement(1, 1, 0)
+		g.Concat(transpose)
+		w, h = h, w
+	}
+	if horizontal {
+		g.Scale(-1, 1)
+	}
+	if vertical {
+		g.Scale(1, -1)
+	}
+	g.Translate(w/2, h/2)
+	return g
+}
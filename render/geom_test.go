@@ -0,0 +1,72 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestGeoMConcatOrder(t *testing.T) {
+	var g GeoM
+	g.Translate(10, 0)
+	g.Scale(2, 3)
+
+	if x, y := g.Apply(1, 1); !near(x, 22) || !near(y, 3) {
+		t.Errorf("translate then scale: got %v,%v, want 22,3", x, y)
+	}
+}
+
+func TestGeoMRotate(t *testing.T) {
+	var g GeoM
+	g.Rotate(math.Pi / 2)
+
+	// Clockwise with y growing downwards
+	if x, y := g.Apply(1, 0); !near(x, 0) || !near(y, 1) {
+		t.Errorf("got %v,%v, want 0,1", x, y)
+	}
+}
+
+func TestGeoMInvert(t *testing.T) {
+	var g GeoM
+	g.Scale(2, 4)
+	g.Rotate(0.3)
+	g.Translate(5, -7)
+
+	inv := g
+	if !inv.Invert() {
+		t.Fatal("transform not invertible")
+	}
+	x, y := g.Apply(3, 9)
+	if x, y = inv.Apply(x, y); !near(x, 3) || !near(y, 9) {
+		t.Errorf("round trip: got %v,%v, want 3,9", x, y)
+	}
+
+	var zero GeoM
+	zero.Scale(0, 1)
+	if zero.Invert() {
+		t.Error("singular transform inverted")
+	}
+}
+
+func TestOptionsTransformFlips(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Options
+		x, y float64 // where the top left corner of a 4x2 image ends up
+	}{
+		{"none", Options{}, 0, 0},
+		{"horizontal", Options{FlipHorizontal: true}, 4, 0},
+		{"vertical", Options{FlipVertical: true}, 0, 2},
+		{"diagonal", Options{FlipDiagonal: true}, 0, 0},
+		{"diagonal horizontal", Options{FlipDiagonal: true, FlipHorizontal: true}, 2, 0},
+	}
+	for _, tt := range tests {
+		g := tt.op.Transform(4, 2)
+		if x, y := g.Apply(0, 0); !near(x, tt.x) || !near(y, tt.y) {
+			t.Errorf("%s: got %v,%v, want %v,%v", tt.name, x, y, tt.x, tt.y)
+		}
+	}
+}
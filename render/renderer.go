@@ -0,0 +1,293 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/talvor/tiled/animation"
+	"github.com/talvor/tiled/clock"
+	"github.com/talvor/tiled/tmx"
+	"github.com/talvor/tiled/tsx"
+)
+
+var ErrTilesetNotFound = errors.New("render: tileset not found")
+
+// TilesetResolver finds loaded tilesets, eg. tsx/manager.TilesetManager.
+type TilesetResolver interface {
+	GetTilesetByName(name string) *tsx.Tileset
+	GetTilesetBySource(source string) *tsx.Tileset
+}
+
+// Renderer draws tiles, map layers and animation frames on a canvas.
+type Renderer struct {
+	Canvas   Canvas
+	Tilesets TilesetResolver
+	// Clock selects the frame of animated tiles, it defaults to clock.Default
+	Clock clock.Clock
+	// LayerOverrides change the visibility, opacity and tint of map layers by name at runtime
+	LayerOverrides tmx.LayerOverrides
+}
+
+func NewRenderer(c Canvas, tilesets TilesetResolver) *Renderer {
+	return &Renderer{
+		Canvas:   c,
+		Tilesets: tilesets,
+		Clock:    clock.Default,
+
+		LayerOverrides: make(tmx.LayerOverrides),
+	}
+}
+
+func (r *Renderer) SetClock(c clock.Clock) {
+	r.Clock = c
+}
+
+// DrawTile draws a tile of the tileset, animated tiles draw the frame for the renderer clock. Canvases
+// implementing TileCanvas draw the tile themselves.
+func (r *Renderer) DrawTile(ts *tsx.Tileset, tileID uint32, op *Options) error {
+	if tc, ok := r.Canvas.(TileCanvas); ok {
+		return tc.DrawTile(ts, tileID, op)
+	}
+
+	tileID = ts.AnimationFrameAt(tileID, r.Clock.Now())
+
+	img, err := r.Canvas.LoadImage(ts.Image.Source)
+	if err != nil {
+		return err
+	}
+
+	rect, err := ts.GetTileRect(tileID)
+	if err != nil {
+		return fmt.Errorf("failed to get tile rect for tile %d in tileset %s: %w", tileID, ts.Name, err)
+	}
+
+	return r.Canvas.DrawImage(img, rect, op)
+}
+
+func (r *Renderer) DrawTileWithName(tilesetName string, tileID uint32, op *Options) error {
+	ts := r.Tilesets.GetTilesetByName(tilesetName)
+	if ts == nil {
+		return fmt.Errorf("tileset: %s %w", tilesetName, ErrTilesetNotFound)
+	}
+	return r.DrawTile(ts, tileID, op)
+}
+
+// DrawLayer draws the tiles of a map layer, flipped as set by their GIDs. The layer is drawn with its
+// visibility, opacity and tint combined with those of its groups and the layer overrides, then the
+// color scale of op.
+func (r *Renderer) DrawLayer(m *tmx.Map, layer *tmx.Layer, op *Options) error {
+	style := layer.Style(r.LayerOverrides)
+	if !style.Visible {
+		return nil
+	}
+	colorScale := layerColorScale(op.ColorScale, style)
+
+	for idx, gid := range layer.Tiles {
+		mts, id := m.DecodeTileGID(gid)
+		if mts == nil {
+			continue
+		}
+		ts := r.Tilesets.GetTilesetBySource(mts.Source)
+		if ts == nil {
+			return fmt.Errorf("tileset: %s %w", mts.Source, ErrTilesetNotFound)
+		}
+
+		posX, posY := layer.GetTilePositionFromIndex(idx, m)
+		flipH, flipV, flipD := gid.Flips()
+
+		tileOp := &Options{
+			ColorScale:     colorScale,
+			FlipHorizontal: flipH,
+			FlipVertical:   flipV,
+			FlipDiagonal:   flipD,
+		}
+		tileOp.GeoM.Concat(op.GeoM)
+		tileOp.GeoM.Translate(float64(posX), float64(posY))
+
+		if err := r.DrawTile(ts, uint32(id), tileOp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DrawObjectGroup draws the visible tile objects of an object group, other objects have no image.
+// Tile objects are scaled to their size and rotated around their bottom left corner like in Tiled.
+func (r *Renderer) DrawObjectGroup(m *tmx.Map, og *tmx.ObjectGroup, op *Options) error {
+	style := og.Style(r.LayerOverrides)
+	if !style.Visible {
+		return nil
+	}
+	colorScale := layerColorScale(op.ColorScale, style)
+	offsetX, offsetY := og.Offset()
+
+	for i := range og.Objects {
+		o, err := m.ResolveTemplate(&og.Objects[i])
+		if err != nil {
+			return err
+		}
+		if !o.Visible || o.GID == 0 {
+			continue
+		}
+
+		gid := tmx.GID(o.GID)
+		mts, id := m.DecodeTileGID(gid)
+		if mts == nil {
+			continue
+		}
+		ts := r.Tilesets.GetTilesetBySource(mts.Source)
+		if ts == nil {
+			return fmt.Errorf("tileset: %s %w", mts.Source, ErrTilesetNotFound)
+		}
+		rect, err := ts.GetTileRect(uint32(id))
+		if err != nil {
+			return fmt.Errorf("failed to get tile rect for tile %d in tileset %s: %w", id, ts.Name, err)
+		}
+
+		flipH, flipV, flipD := gid.Flips()
+		objOp := &Options{
+			ColorScale:     colorScale,
+			FlipHorizontal: flipH,
+			FlipVertical:   flipV,
+			FlipDiagonal:   flipD,
+		}
+		objOp.GeoM = tileObjectGeoM(&o, rect.Dx(), rect.Dy())
+		objOp.GeoM.Concat(op.GeoM)
+		objOp.GeoM.Translate(o.X+float64(offsetX), o.Y+float64(offsetY))
+
+		if err := r.DrawTile(ts, uint32(id), objOp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DrawImageLayer draws the image of an image layer. Repeating image layers are drawn once.
+func (r *Renderer) DrawImageLayer(m *tmx.Map, il *tmx.ImageLayer, op *Options) error {
+	style := il.Style(r.LayerOverrides)
+	if !style.Visible || il.Image.Source == "" {
+		return nil
+	}
+
+	img, err := r.Canvas.LoadImage(il.Image.Source)
+	if err != nil {
+		return err
+	}
+
+	offsetX, offsetY := il.Offset()
+	imgOp := &Options{ColorScale: layerColorScale(op.ColorScale, style)}
+	imgOp.GeoM.Concat(op.GeoM)
+	imgOp.GeoM.Translate(float64(offsetX), float64(offsetY))
+
+	return r.Canvas.DrawImage(img, img.Bounds(), imgOp)
+}
+
+// DrawMapLayer draws the named layer of the map, a group draws all of its layers.
+func (r *Renderer) DrawMapLayer(m *tmx.Map, layerName string, op *Options) error {
+	n, err := m.FindLayer(layerName)
+	if err != nil {
+		return err
+	}
+	return r.drawNode(m, n, op)
+}
+
+// DrawMap draws every layer of the map in order. The geometry of op transforms each tile, tile object
+// and image before it is placed on the map, like the camera of tmx/renderer, so a scale changes the size
+// of the tiles but not their positions.
+func (r *Renderer) DrawMap(m *tmx.Map, op *Options) error {
+	for i := range m.Children {
+		if err := r.drawNode(m, &m.Children[i], op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) drawNode(m *tmx.Map, n *tmx.LayerNode, op *Options) error {
+	switch n.Kind {
+	case tmx.LayerKindTile:
+		return r.DrawLayer(m, n.Layer, op)
+	case tmx.LayerKindObject:
+		return r.DrawObjectGroup(m, n.ObjectGroup, op)
+	case tmx.LayerKindImage:
+		return r.DrawImageLayer(m, n.ImageLayer, op)
+	case tmx.LayerKindGroup:
+		if !n.Group.Style(r.LayerOverrides).Visible {
+			return nil
+		}
+		for i := range n.Group.Children {
+			if err := r.drawNode(m, &n.Group.Children[i], op); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// layerColorScale returns the color scale c with the tint and opacity of a layer applied.
+func layerColorScale(c ColorScale, style tmx.LayerStyle) ColorScale {
+	c.Tint(style.Tint)
+	c.ScaleAlpha(style.Alpha())
+	return c
+}
+
+// tileObjectGeoM returns the transform of a w x h tile drawn as the tile object o, with the bottom left
+// corner of the object at the origin.
+func tileObjectGeoM(o *tmx.Object, w, h int) GeoM {
+	var g GeoM
+	width, height := o.Width, o.Height
+	if width == 0 || height == 0 {
+		width, height = float64(w), float64(h)
+	}
+	g.Scale(width/float64(w), height/float64(h))
+	g.Translate(0, -height)
+	g.Rotate(o.Rotation * math.Pi / 180)
+	return g
+}
+
+// DrawFrame draws a frame of the animation with its origin at the translation of op.
+func (r *Renderer) DrawFrame(ani *animation.Animation, frame animation.Frame, op *Options) error {
+	for _, part := range frame.Parts {
+		if part.TileID == -1 {
+			continue
+		}
+
+		tilesets := ani.Tilesets
+		if part.Tileset != "" {
+			tilesets = []string{part.Tileset}
+		}
+		for _, name := range tilesets {
+			ts := r.Tilesets.GetTilesetByName(name)
+			if ts == nil {
+				return fmt.Errorf("tileset: %s %w", name, ErrTilesetNotFound)
+			}
+
+			partOp := &Options{
+				ColorScale:     op.ColorScale,
+				FlipHorizontal: part.FlipHorizontal,
+				FlipVertical:   part.FlipVertical,
+			}
+			pos := ani.PartPosition(part, ts.TileWidth)
+			partOp.GeoM.Translate(float64(pos.X), float64(pos.Y))
+			partOp.GeoM.Concat(op.GeoM)
+			if err := r.DrawTile(ts, uint32(part.TileID), partOp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DrawPlayer advances the player and draws its current frame.
+func (r *Renderer) DrawPlayer(player *animation.AnimationPlayer, op *Options) error {
+	player.Update()
+	if !player.Visible() {
+		return nil
+	}
+	frame := player.GetCurrentFrame()
+	if len(frame.Parts) == 0 {
+		return nil
+	}
+	return r.DrawFrame(player.Animation, frame, op)
+}
@@ -0,0 +1,181 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/talvor/tiled/tmx"
+	"github.com/talvor/tiled/tsx"
+)
+
+var (
+	red  = color.RGBA{R: 0xff, A: 0xff}
+	blue = color.RGBA{B: 0xff, A: 0xff}
+)
+
+// tilesets resolves the tilesets of the test map.
+type tilesets map[string]*tsx.Tileset
+
+func (t tilesets) GetTilesetByName(name string) *tsx.Tileset {
+	for _, ts := range t {
+		if ts.Name == name {
+			return ts
+		}
+	}
+	return nil
+}
+
+func (t tilesets) GetTilesetBySource(source string) *tsx.Tileset {
+	return t[source]
+}
+
+// testMap loads a map of 2x2 tiles, drawn from a tileset whose tile 0 is red with a blue right column
+// and tile 1 is blue.
+func testMap(t *testing.T, layers string) (*tmx.Map, *Renderer, *Software) {
+	t.Helper()
+	dir := t.TempDir()
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="2" height="1" tilewidth="2" tileheight="2">
+ <tileset firstgid="1" source="tiles.tsx"/>
+` + layers + `
+</map>`
+	if err := os.WriteFile(filepath.Join(dir, "test.tmx"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := tmx.LoadFile(filepath.Join(dir, "test.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := range 2 {
+		img.Set(0, y, red)
+		img.Set(1, y, blue)
+		img.Set(2, y, blue)
+		img.Set(3, y, blue)
+	}
+	ts := &tsx.Tileset{Name: "tiles", TileWidth: 2, TileHeight: 2, TileCount: 2, Columns: 2, Image: tsx.Image{Source: "tiles.png", Width: 4, Height: 2}}
+
+	canvas := NewSoftware(4, 2)
+	canvas.images["tiles.png"] = img
+	return m, NewRenderer(canvas, tilesets{filepath.Join(dir, "tiles.tsx"): ts}), canvas
+}
+
+func TestDrawMapFlipsTiles(t *testing.T) {
+	m, r, canvas := testMap(t, ` <layer id="1" name="ground" width="2" height="1">
+  <data encoding="csv">1,2147483649</data>
+ </layer>`)
+
+	if err := r.DrawMap(m, &Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []color.RGBA{red, blue, blue, red}
+	for x, c := range want {
+		if got := canvas.Image().RGBAAt(x, 0); got != c {
+			t.Errorf("pixel %d: got %v, want %v", x, got, c)
+		}
+	}
+}
+
+func TestDrawMapLayerStyles(t *testing.T) {
+	m, r, canvas := testMap(t, ` <group id="1" name="roof" opacity="0.5">
+  <layer id="2" name="tiles" width="2" height="1">
+   <data encoding="csv">2,2</data>
+  </layer>
+ </group>
+ <layer id="3" name="collider" width="2" height="1" visible="0">
+  <data encoding="csv">1,1</data>
+ </layer>`)
+
+	if err := r.DrawMap(m, &Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := canvas.Image().RGBAAt(0, 0); got.B != 0x80 || got.A != 0x80 || got.R != 0 {
+		t.Errorf("half transparent group drew %v", got)
+	}
+
+	r.LayerOverrides.SetVisible("collider", true)
+	r.LayerOverrides.SetOpacity("roof", 1)
+	canvas.Clear(color.Transparent)
+	if err := r.DrawMap(m, &Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := canvas.Image().RGBAAt(0, 0); got != red {
+		t.Errorf("hidden layer shown by the overrides drew %v, want %v", got, red)
+	}
+}
+
+func TestDrawMapCamera(t *testing.T) {
+	m, r, canvas := testMap(t, ` <layer id="1" name="ground" width="2" height="1">
+  <data encoding="csv">2,1</data>
+ </layer>`)
+
+	// The camera applies to each tile before it is placed, the positions of the tiles are not scaled
+	op := &Options{}
+	op.GeoM.Scale(0.5, 0.5)
+	if err := r.DrawMap(m, op); err != nil {
+		t.Fatal(err)
+	}
+	for x, want := range []uint8{0xff, 0, 0xff, 0} {
+		if got := canvas.Image().RGBAAt(x, 0); got.A != want {
+			t.Errorf("pixel %d,0: got %v, want alpha %#x", x, got, want)
+		}
+	}
+	if got := canvas.Image().RGBAAt(0, 0); got != blue {
+		t.Errorf("first tile drew %v, want %v", got, blue)
+	}
+}
+
+// tileCanvas records the tiles drawn through it.
+type tileCanvas struct {
+	*Software
+	tiles []uint32
+}
+
+func (c *tileCanvas) DrawTile(ts *tsx.Tileset, tileID uint32, op *Options) error {
+	c.tiles = append(c.tiles, tileID)
+	return nil
+}
+
+func TestTileCanvasDrawsTiles(t *testing.T) {
+	m, r, canvas := testMap(t, ` <layer id="1" name="ground" width="2" height="1">
+  <data encoding="csv">2,1</data>
+ </layer>`)
+	tc := &tileCanvas{Software: canvas}
+	r.Canvas = tc
+
+	if err := r.DrawMap(m, &Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tc.tiles) != 2 || tc.tiles[0] != 1 || tc.tiles[1] != 0 {
+		t.Errorf("tile canvas drew %v, want [1 0]", tc.tiles)
+	}
+	if got := canvas.Image().RGBAAt(0, 0); got.A != 0 {
+		t.Errorf("renderer drew the tileset image to a tile canvas: %v", got)
+	}
+}
+
+// foreignCanvas loads images the software canvas cannot draw.
+type foreignCanvas struct {
+	*Software
+}
+
+func (c *foreignCanvas) LoadImage(source string) (Image, error) {
+	return image.NewGray(image.Rect(0, 0, 4, 2)), nil
+}
+
+func TestDrawUnsupportedImage(t *testing.T) {
+	m, r, canvas := testMap(t, ` <layer id="1" name="ground" width="2" height="1">
+  <data encoding="csv">2,1</data>
+ </layer>`)
+	r.Canvas = &foreignCanvas{Software: canvas}
+
+	if err := r.DrawMap(m, &Options{}); !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("got %v, want %v", err, ErrUnsupportedImage)
+	}
+}
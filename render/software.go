@@ -0,0 +1,222 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/png"
+	"math"
+	"os"
+)
+
+var ErrUnsupportedImage = errors.New("render: image was not loaded by this canvas")
+
+// Software is a Canvas drawing into an image.RGBA without a GPU or display.
+// Images are sampled with the nearest pixel.
+type Software struct {
+	Target *image.RGBA
+
+	images map[string]*image.RGBA
+}
+
+func NewSoftware(width, height int) *Software {
+	return NewSoftwareWithTarget(image.NewRGBA(image.Rect(0, 0, width, height)))
+}
+
+func NewSoftwareWithTarget(target *image.RGBA) *Software {
+	return &Software{
+		Target: target,
+		images: make(map[string]*image.RGBA),
+	}
+}
+
+// Image returns the drawn image.
+func (s *Software) Image() *image.RGBA {
+	return s.Target
+}
+
+// Clear fills the target with c.
+func (s *Software) Clear(c color.Color) {
+	draw.Draw(s.Target, s.Target.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// LoadImage decodes an image file, images are cached by source.
+func (s *Software) LoadImage(source string) (Image, error) {
+	if img, ok := s.images[source]; ok {
+		return img, nil
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image %s: %w", source, err)
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image %s: %w", source, err)
+	}
+
+	img := AddImage(src)
+	s.images[source] = img
+	return img, nil
+}
+
+// AddImage converts an image to one that can be drawn by a Software canvas.
+func AddImage(src image.Image) *image.RGBA {
+	if img, ok := src.(*image.RGBA); ok {
+		return img
+	}
+	b := src.Bounds()
+	img := image.NewRGBA(b)
+	draw.Draw(img, b, src, b.Min, draw.Src)
+	return img
+}
+
+// DrawImage draws the region src of img with source-over compositing.
+func (s *Software) DrawImage(img Image, src image.Rectangle, op *Options) error {
+	srcImg, ok := img.(*image.RGBA)
+	if !ok {
+		return fmt.Errorf("image: %T %w", img, ErrUnsupportedImage)
+	}
+	src = src.Intersect(srcImg.Bounds())
+	if src.Empty() {
+		return nil
+	}
+
+	w, h := src.Dx(), src.Dy()
+	g := op.Transform(w, h)
+
+	// Destination bounds of the transformed image
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [4][2]float64{{0, 0}, {float64(w), 0}, {0, float64(h)}, {float64(w), float64(h)}} {
+		x, y := g.Apply(corner[0], corner[1])
+		minX, minY = min(minX, x), min(minY, y)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+	}
+	dst := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	dst = dst.Intersect(s.Target.Bounds())
+	if dst.Empty() {
+		return nil
+	}
+
+	inv := g
+	if !inv.Invert() {
+		return nil
+	}
+
+	cs := op.ColorScale
+	scale := [4]float32{cs.R(), cs.G(), cs.B(), cs.A()}
+
+	for y := dst.Min.Y; y < dst.Max.Y; y++ {
+		for x := dst.Min.X; x < dst.Max.X; x++ {
+			// Sample at the pixel centre
+			sx, sy := inv.Apply(float64(x)+0.5, float64(y)+0.5)
+			if sx < 0 || sy < 0 || sx >= float64(w) || sy >= float64(h) {
+				continue
+			}
+
+			si := srcImg.PixOffset(src.Min.X+int(sx), src.Min.Y+int(sy))
+			a := scaleChannel(srcImg.Pix[si+3], scale[3])
+			if a == 0 {
+				continue
+			}
+			r := min(scaleChannel(srcImg.Pix[si], scale[0]), a)
+			gr := min(scaleChannel(srcImg.Pix[si+1], scale[1]), a)
+			b := min(scaleChannel(srcImg.Pix[si+2], scale[2]), a)
+
+			di := s.Target.PixOffset(x, y)
+			pix := s.Target.Pix[di : di+4 : di+4]
+			rest := 255 - uint32(a)
+			pix[0] = uint8(uint32(r) + uint32(pix[0])*rest/255)
+			pix[1] = uint8(uint32(gr) + uint32(pix[1])*rest/255)
+			pix[2] = uint8(uint32(b) + uint32(pix[2])*rest/255)
+			pix[3] = uint8(uint32(a) + uint32(pix[3])*rest/255)
+		}
+	}
+	return nil
+}
+
+func scaleChannel(v uint8, scale float32) uint8 {
+	if scale == 1 {
+		return v
+	}
+	return uint8(max(0, min(255, math.Round(float64(float32(v)*scale)))))
+}
+
+// DrawLine draws a 1 pixel wide line between the pixels containing (x0, y0) and (x1, y1).
+func (s *Software) DrawLine(x0, y0, x1, y1 float64, c color.Color) {
+	ax, ay := int(math.Floor(x0)), int(math.Floor(y0))
+	bx, by := int(math.Floor(x1)), int(math.Floor(y1))
+
+	dx, dy := abs(bx-ax), -abs(by-ay)
+	sx, sy := 1, 1
+	if ax > bx {
+		sx = -1
+	}
+	if ay > by {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		s.blendPixel(ax, ay, c)
+		if ax == bx && ay == by {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			ax += sx
+		}
+		if e2 <= dx {
+			err += dx
+			ay += sy
+		}
+	}
+}
+
+// DrawPath draws lines between consecutive points, closed draws a line back to the first point.
+func (s *Software) DrawPath(points [][2]float64, closed bool, c color.Color) {
+	for i := 1; i < len(points); i++ {
+		s.DrawLine(points[i-1][0], points[i-1][1], points[i][0], points[i][1], c)
+	}
+	if closed && len(points) > 2 {
+		last := points[len(points)-1]
+		s.DrawLine(last[0], last[1], points[0][0], points[0][1], c)
+	}
+}
+
+// DrawRect draws the 1 pixel wide outline of r.
+func (s *Software) DrawRect(r image.Rectangle, c color.Color) {
+	if r.Empty() {
+		return
+	}
+	x0, y0 := float64(r.Min.X), float64(r.Min.Y)
+	x1, y1 := float64(r.Max.X-1), float64(r.Max.Y-1)
+	s.DrawPath([][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}, true, c)
+}
+
+func (s *Software) blendPixel(x, y int, c color.Color) {
+	if !(image.Point{x, y}).In(s.Target.Bounds()) {
+		return
+	}
+	r, g, b, a := c.RGBA()
+	i := s.Target.PixOffset(x, y)
+	pix := s.Target.Pix[i : i+4 : i+4]
+	rest := 0xffff - a
+	pix[0] = uint8((r + uint32(pix[0])*0x101*rest/0xffff) >> 8)
+	pix[1] = uint8((g + uint32(pix[1])*0x101*rest/0xffff) >> 8)
+	pix[2] = uint8((b + uint32(pix[2])*0x101*rest/0xffff) >> 8)
+	pix[3] = uint8((a + uint32(pix[3])*0x101*rest/0xffff) >> 8)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -1,6 +1,9 @@
 package tiled
 
 import (
+	"errors"
+	"fmt"
+
 	anim "github.com/talvor/tiled/animation/manager"
 	anir "github.com/talvor/tiled/animation/renderer"
 	tmxm "github.com/talvor/tiled/tmx/manager"
@@ -9,12 +12,28 @@ import (
 	tsxr "github.com/talvor/tiled/tsx/renderer"
 )
 
+// NewAnimationRenderer loads the animations and tilesets of the directories and prints the loading and
+// validation problems found, see LoadAnimationRenderer.
 func NewAnimationRenderer(animationBaseDirs []string, tilesetBaseDirs []string) *anir.Renderer {
-	am := anim.NewManager(animationBaseDirs)
+	r, err := LoadAnimationRenderer(animationBaseDirs, tilesetBaseDirs)
+	if err != nil {
+		fmt.Printf("Error loading animations: %v\n", err)
+	}
+	return r
+}
+
+// LoadAnimationRenderer loads the animations and tilesets of the directories and validates the animations
+// against the tilesets. It returns every loading and validation problem along with the renderer, files
+// that failed to load are left out while animations failing validation are kept so callers may decide.
+func LoadAnimationRenderer(animationBaseDirs []string, tilesetBaseDirs []string) (*anir.Renderer, error) {
+	am, loadErr := anim.LoadManager(animationBaseDirs)
 	tsm := tsxm.NewManager(tilesetBaseDirs)
+	for _, ts := range am.Tilesets {
+		tsm.RegisterTileset(ts)
+	}
 	tsr := tsxr.NewRenderer(tsm)
 
-	return anir.NewRenderer(am, tsr)
+	return anir.NewRenderer(am, tsr), errors.Join(loadErr, am.Validate(tsm))
 }
 
 func NewTilesetRenderer(tilesetBaseDirs []string) *tsxr.Renderer {
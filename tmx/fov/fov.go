@@ -0,0 +1,132 @@
+package fov
+
+// Multipliers transforming the first octant into each of the eight octants
+var octants = [8][4]int{
+	{1, 0, 0, 1},
+	{0, 1, 1, 0},
+	{0, -1, 1, 0},
+	{-1, 0, 0, 1},
+	{-1, 0, 0, -1},
+	{0, -1, -1, 0},
+	{0, 1, -1, 0},
+	{1, 0, 0, -1},
+}
+
+// Compute clears vis and marks the tiles visible from (originX, originY) using recursive shadowcasting.
+// Opaque tiles are visible but block the tiles behind them. A radius of zero or less is unlimited.
+func Compute(vis *Visibility, opaque OpacityFunc, originX, originY int, radius int) {
+	vis.Clear()
+	if !vis.InBounds(originX, originY) {
+		return
+	}
+	// An unlimited radius scans every row of the map without a distance limit
+	radiusSq := radius * radius
+	if radius <= 0 {
+		radius = max(vis.Width, vis.Height)
+		radiusSq = -1
+	}
+
+	vis.Set(originX, originY, true)
+	for _, o := range octants {
+		castLight(vis, opaque, originX, originY, radius, radiusSq, 1, 1.0, 0.0, o[0], o[1], o[2], o[3])
+	}
+}
+
+// castLight scans the rows of an octant from row up to radius. A negative radiusSq has no distance limit.
+func castLight(vis *Visibility, opaque OpacityFunc, ox, oy, radius, radiusSq, row int, start, end float64, xx, xy, yx, yy int) {
+	if start < end {
+		return
+	}
+
+	newStart := 0.0
+	for j := row; j <= radius; j++ {
+		dy := -j
+		blocked := false
+		for dx := -j; dx <= 0; dx++ {
+			x := ox + dx*xx + dy*xy
+			y := oy + dx*yx + dy*yy
+
+			leftSlope := (float64(dx) - 0.5) / (float64(dy) + 0.5)
+			rightSlope := (float64(dx) + 0.5) / (float64(dy) - 0.5)
+			if start < rightSlope {
+				continue
+			}
+			if end > leftSlope {
+				break
+			}
+
+			if radiusSq < 0 || dx*dx+dy*dy <= radiusSq {
+				vis.Set(x, y, true)
+			}
+
+			if blocked {
+				if opaque(x, y) {
+					newStart = rightSlope
+					continue
+				}
+				blocked = false
+				start = newStart
+			} else if opaque(x, y) && j < radius {
+				blocked = true
+				castLight(vis, opaque, ox, oy, radius, radiusSq, j+1, start, leftSlope, xx, xy, yx, yy)
+				newStart = rightSlope
+			}
+		}
+		if blocked {
+			break
+		}
+	}
+}
+
+// Line returns the tiles on the Bresenham line from (x0, y0) to (x1, y1), both ends included.
+func Line(x0, y0, x1, y1 int) [][2]int {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	points := make([][2]int, 0, max(dx, -dy)+1)
+	err := dx + dy
+	for {
+		points = append(points, [2]int{x0, y0})
+		if x0 == x1 && y0 == y1 {
+			return points
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// LineOfSight reports whether no opaque tile lies between the two tiles. The end points themselves
+// may be opaque, so a wall is in sight of a tile next to it.
+func LineOfSight(x0, y0, x1, y1 int, opaque OpacityFunc) bool {
+	points := Line(x0, y0, x1, y1)
+	if len(points) <= 2 {
+		return true
+	}
+	for _, p := range points[1 : len(points)-1] {
+		if opaque(p[0], p[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
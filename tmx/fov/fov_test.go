@@ -0,0 +1,138 @@
+package fov
+
+import (
+	"strings"
+	"testing"
+)
+
+// grid parses a map where '#' is opaque, returning its size and opacity.
+func grid(rows ...string) (int, int, OpacityFunc) {
+	width, height := len(rows[0]), len(rows)
+	opaque := make([]bool, width*height)
+	for y, row := range rows {
+		for x, c := range row {
+			opaque[y*width+x] = c == '#'
+		}
+	}
+	return width, height, gridOpacity(width, height, opaque)
+}
+
+// render draws the visibility with '.' for visible and ' ' for hidden tiles.
+func render(vis *Visibility) string {
+	var b strings.Builder
+	for y := range vis.Height {
+		for x := range vis.Width {
+			if vis.Visible(x, y) {
+				b.WriteByte('.')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func TestComputeOpenRoom(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		x, y          int
+	}{
+		{"center", 5, 5, 2, 2},
+		{"top left corner", 10, 10, 0, 0},
+		{"bottom right corner", 10, 10, 9, 9},
+		{"corner of a corridor", 12, 3, 0, 2},
+	}
+	for _, tt := range tests {
+		rows := make([]string, tt.height)
+		for i := range rows {
+			rows[i] = strings.Repeat(" ", tt.width)
+		}
+		w, h, opaque := grid(rows...)
+		vis := NewVisibility(w, h)
+		Compute(vis, opaque, tt.x, tt.y, 0)
+
+		for y := range h {
+			for x := range w {
+				if !vis.Visible(x, y) {
+					t.Fatalf("%s: tile %d,%d not visible in an open room:\n%s", tt.name, x, y, render(vis))
+				}
+			}
+		}
+	}
+}
+
+func TestComputeWallCastsShadow(t *testing.T) {
+	w, h, opaque := grid(
+		"       ",
+		"       ",
+		"   #   ",
+		"       ",
+		"       ",
+	)
+	vis := NewVisibility(w, h)
+	Compute(vis, opaque, 0, 2, 0)
+
+	if !vis.Visible(3, 2) {
+		t.Errorf("wall is not visible:\n%s", render(vis))
+	}
+	for x := 4; x < w; x++ {
+		if vis.Visible(x, 2) {
+			t.Errorf("tile %d,2 behind the wall is visible:\n%s", x, render(vis))
+		}
+	}
+	if !vis.Visible(6, 0) || !vis.Visible(6, 4) {
+		t.Errorf("tiles beside the shadow are hidden:\n%s", render(vis))
+	}
+}
+
+func TestComputeRadius(t *testing.T) {
+	vis := NewVisibility(9, 9)
+	Compute(vis, func(x, y int) bool { return false }, 4, 4, 2)
+
+	if !vis.Visible(6, 4) || !vis.Visible(4, 2) {
+		t.Errorf("tiles within the radius are hidden:\n%s", render(vis))
+	}
+	if vis.Visible(7, 4) || vis.Visible(6, 6) {
+		t.Errorf("tiles beyond the radius are visible:\n%s", render(vis))
+	}
+}
+
+func TestComputeOriginOutOfBounds(t *testing.T) {
+	vis := NewVisibility(3, 3)
+	vis.Set(1, 1, true)
+	Compute(vis, func(x, y int) bool { return false }, -1, 0, 0)
+
+	if strings.Contains(render(vis), ".") {
+		t.Errorf("tiles visible from outside the map:\n%s", render(vis))
+	}
+}
+
+func TestLine(t *testing.T) {
+	points := Line(0, 0, 4, 2)
+	if len(points) != 5 {
+		t.Fatalf("got %d points, want 5: %v", len(points), points)
+	}
+	if points[0] != [2]int{0, 0} || points[4] != [2]int{4, 2} {
+		t.Errorf("line does not include both ends: %v", points)
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	_, _, opaque := grid(
+		"     ",
+		"  #  ",
+		"     ",
+	)
+
+	if LineOfSight(0, 1, 4, 1, opaque) {
+		t.Error("sight through a wall")
+	}
+	if !LineOfSight(0, 0, 4, 0, opaque) {
+		t.Error("no sight along an open row")
+	}
+	if !LineOfSight(1, 1, 2, 1, opaque) {
+		t.Error("no sight of the adjacent wall")
+	}
+}
@@ -0,0 +1,89 @@
+package fov
+
+import (
+	"strconv"
+
+	"github.com/talvor/tiled/tmx"
+	"github.com/talvor/tiled/tsx"
+)
+
+// OpacityFunc reports whether the tile at (x, y) blocks sight.
+type OpacityFunc func(x, y int) bool
+
+type TilesetResolver interface {
+	GetTilesetBySource(source string) *tsx.Tileset
+}
+
+// LayerOpacity treats every non empty tile of the layer as opaque.
+func LayerOpacity(m *tmx.Map, layerName string) (OpacityFunc, error) {
+	layer, err := m.GetLayer(layerName)
+	if err != nil {
+		return nil, err
+	}
+
+	opaque := make([]bool, len(layer.Tiles))
+	for i, gid := range layer.Tiles {
+		opaque[i] = gid&tmx.GIDMask != 0
+	}
+	return gridOpacity(layer.Width, layer.Height, opaque), nil
+}
+
+// PropertyOpacity treats tiles as opaque when their tileset tile has the boolean property set to true.
+// Only the named layers are considered, or every layer of the map when none are given.
+func PropertyOpacity(m *tmx.Map, resolver TilesetResolver, property string, layerNames ...string) (OpacityFunc, error) {
+	var layers []*tmx.Layer
+	if len(layerNames) == 0 {
+		for i := range m.Layers {
+			layers = append(layers, &m.Layers[i])
+		}
+	}
+	for _, name := range layerNames {
+		layer, err := m.GetLayer(name)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	opaque := make([]bool, m.Width*m.Height)
+	for _, layer := range layers {
+		for i, gid := range layer.Tiles {
+			if i >= len(opaque) || opaque[i] {
+				continue
+			}
+			ts, id := m.DecodeTileGID(gid & tmx.GIDMask)
+			if ts == nil {
+				continue
+			}
+			tileset := resolver.GetTilesetBySource(ts.Source)
+			if tileset == nil {
+				continue
+			}
+			opaque[i] = tileHasProperty(tileset, uint32(id), property)
+		}
+	}
+	return gridOpacity(m.Width, m.Height, opaque), nil
+}
+
+func tileHasProperty(ts *tsx.Tileset, id uint32, property string) bool {
+	tile, err := ts.GetTileByID(id)
+	if err != nil {
+		return false
+	}
+	p, err := tile.Properties.Get(property)
+	if err != nil {
+		return false
+	}
+	v, _ := strconv.ParseBool(p.Value)
+	return v
+}
+
+// gridOpacity treats tiles outside the grid as opaque.
+func gridOpacity(width int, height int, opaque []bool) OpacityFunc {
+	return func(x, y int) bool {
+		if x < 0 || y < 0 || x >= width || y >= height {
+			return true
+		}
+		return opaque[y*width+x]
+	}
+}
@@ -0,0 +1,47 @@
+package fov
+
+// Visibility is a bitmap with one entry per map tile.
+type Visibility struct {
+	Width  int
+	Height int
+	cells  []bool
+}
+
+func NewVisibility(width int, height int) *Visibility {
+	return &Visibility{
+		Width:  width,
+		Height: height,
+		cells:  make([]bool, width*height),
+	}
+}
+
+func (v *Visibility) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < v.Width && y < v.Height
+}
+
+func (v *Visibility) Visible(x, y int) bool {
+	if !v.InBounds(x, y) {
+		return false
+	}
+	return v.cells[y*v.Width+x]
+}
+
+func (v *Visibility) Set(x, y int, visible bool) {
+	if !v.InBounds(x, y) {
+		return
+	}
+	v.cells[y*v.Width+x] = visible
+}
+
+func (v *Visibility) Clear() {
+	clear(v.cells)
+}
+
+// Merge marks every tile visible in o as visible in v. Both bitmaps must have the same size.
+func (v *Visibility) Merge(o *Visibility) {
+	for i, visible := range o.cells {
+		if visible && i < len(v.cells) {
+			v.cells[i] = true
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package tmx
+
+import "math"
+
+// Rect is an axis aligned rectangle in map pixel coordinates.
+type Rect struct {
+	MinX float64
+	MinY float64
+	MaxX float64
+	MaxY float64
+}
+
+func (r Rect) Intersects(o Rect) bool {
+	return r.MinX <= o.MaxX && o.MinX <= r.MaxX && r.MinY <= o.MaxY && o.MinY <= r.MaxY
+}
+
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.MinX && x <= r.MaxX && y >= r.MinY && y <= r.MaxY
+}
+
+func (r Rect) Union(o Rect) Rect {
+	return Rect{
+		MinX: math.Min(r.MinX, o.MinX),
+		MinY: math.Min(r.MinY, o.MinY),
+		MaxX: math.Max(r.MaxX, o.MaxX),
+		MaxY: math.Max(r.MaxY, o.MaxY),
+	}
+}
+
+// ClassName returns the class of the object, falling back to the type attribute
+// written by versions of Tiled before 1.9.
+func (o *Object) ClassName() string {
+	if o.Class != "" {
+		return o.Class
+	}
+	return o.Type
+}
+
+// Outline returns the points describing the object shape in map coordinates with
+// the object rotation applied. Polygons and polylines return their points, every
+// other object returns the four corners of its rectangle.
+func (o *Object) Outline() [][2]float64 {
+	var local [][2]float64
+
+	if points, ok := o.decodeShape(); ok {
+		local = make([][2]float64, len(points))
+		for i, p := range points {
+			local[i] = [2]float64{p.X, p.Y}
+		}
+	} else {
+		// Tile objects are anchored at their bottom left corner
+		top := 0.0
+		if o.GID != 0 {
+			top = -o.Height
+		}
+		local = [][2]float64{
+			{0, top},
+			{o.Width, top},
+			{o.Width, top + o.Height},
+			{0, top + o.Height},
+		}
+	}
+
+	sin, cos := math.Sincos(o.Rotation * math.Pi / 180)
+	outline := make([][2]float64, len(local))
+	for i, p := range local {
+		outline[i] = [2]float64{
+			o.X + p[0]*cos - p[1]*sin,
+			o.Y + p[0]*sin + p[1]*cos,
+		}
+	}
+	return outline
+}
+
+// Bounds returns the axis aligned bounding box of the rotated object.
+func (o *Object) Bounds() Rect {
+	outline := o.Outline()
+
+	r := Rect{MinX: outline[0][0], MinY: outline[0][1], MaxX: outline[0][0], MaxY: outline[0][1]}
+	for _, p := range outline[1:] {
+		r = r.Union(Rect{MinX: p[0], MinY: p[1], MaxX: p[0], MaxY: p[1]})
+	}
+	return r
+}
+
+// Contains reports whether the point lies inside the rotated rectangle or polygon of the object.
+// Polylines and point objects never contain a point.
+func (o *Object) Contains(x, y float64) bool {
+	if len(o.PolyLines) > 0 && len(o.Polygons) == 0 {
+		return false
+	}
+	if len(o.Polygons) == 0 && (o.Width == 0 || o.Height == 0) {
+		return false
+	}
+
+	outline := o.Outline()
+	if len(outline) < 3 {
+		return false
+	}
+
+	// Even-odd rule ray casting
+	inside := false
+	for i, j := 0, len(outline)-1; i < len(outline); j, i = i, i+1 {
+		xi, yi := outline[i][0], outline[i][1]
+		xj, yj := outline[j][0], outline[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (o *Object) decodeShape() ([]FloatPoint, bool) {
+	var (
+		points []FloatPoint
+		err    error
+	)
+	switch {
+	case len(o.Polygons) > 0:
+		points, err = o.Polygons[0].DecodeFloat()
+	case len(o.PolyLines) > 0:
+		points, err = o.PolyLines[0].DecodeFloat()
+	default:
+		return nil, false
+	}
+	if err != nil || len(points) == 0 {
+		return nil, false
+	}
+	return points, true
+}
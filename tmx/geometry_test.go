@@ -0,0 +1,152 @@
+package tmx
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecodePointsKeepsFractions(t *testing.T) {
+	points, err := decodePoints("0,0 10.5,0 10.5,7.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []FloatPoint{{0, 0}, {10.5, 0}, {10.5, 7.25}}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("point %d: got %v, want %v", i, points[i], want[i])
+		}
+	}
+}
+
+func TestObjectContainsFractionalPolygon(t *testing.T) {
+	o := &Object{
+		X:        100,
+		Y:        100,
+		Polygons: []Polygon{{Points: "0,0 0.6,0 0.6,0.6 0,0.6"}},
+	}
+
+	if !o.Contains(100.5, 100.5) {
+		t.Error("point inside the polygon is not contained")
+	}
+	// Rounded to whole pixels the polygon would reach 101
+	if o.Contains(100.8, 100.8) {
+		t.Error("point outside the polygon is contained")
+	}
+}
+
+func TestObjectIndexUpdate(t *testing.T) {
+	o := &Object{ID: 1, X: 0, Y: 0, Width: 8, Height: 8}
+	idx := NewObjectIndex(16)
+	idx.Insert(o)
+
+	o.X, o.Y = 100, 100
+	if got := idx.QueryRect(Rect{MinX: 96, MinY: 96, MaxX: 112, MaxY: 112}); len(got) != 0 {
+		t.Fatalf("moved object found before Update: %v", got)
+	}
+
+	idx.Update(o)
+	if got := idx.QueryRect(Rect{MinX: 96, MinY: 96, MaxX: 112, MaxY: 112}); len(got) != 1 {
+		t.Fatalf("moved object not found after Update, got %d objects", len(got))
+	}
+	if got := idx.QueryRect(Rect{MinX: 0, MinY: 0, MaxX: 8, MaxY: 8}); len(got) != 0 {
+		t.Fatalf("moved object still found at its old position: %v", got)
+	}
+}
+
+func TestDecodePointsRoundsToPixels(t *testing.T) {
+	p := &Polygon{Points: "0,0 10.5,0 10.4,7.25"}
+	points, err := p.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Point{{0, 0}, {11, 0}, {10, 7}}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("point %d: got %v, want %v", i, points[i], want[i])
+		}
+	}
+}
+
+func TestObjectIndexQueryRotated(t *testing.T) {
+	// A 32x8 rectangle turned a quarter clockwise around its top left corner covers x -8..0, y 0..32
+	o := &Object{ID: 1, Width: 32, Height: 8, Rotation: 90}
+	idx := NewObjectIndex(16)
+	idx.Insert(o)
+
+	tests := []struct {
+		name string
+		r    Rect
+		want int
+	}{
+		{"rotated bounds", Rect{MinX: -6, MinY: 20, MaxX: -2, MaxY: 24}, 1},
+		{"unrotated bounds", Rect{MinX: 10, MinY: 1, MaxX: 20, MaxY: 4}, 0},
+	}
+	for _, tt := range tests {
+		if got := idx.QueryRect(tt.r); len(got) != tt.want {
+			t.Errorf("%s: got %d objects, want %d", tt.name, len(got), tt.want)
+		}
+	}
+}
+
+func TestObjectIndexQueryRadius(t *testing.T) {
+	near := &Object{ID: 2, X: 100, Y: 100, Width: 10, Height: 10}
+	far := &Object{ID: 1, X: 200, Y: 200, Width: 10, Height: 10}
+	idx := NewObjectIndex(32)
+	idx.Insert(near)
+	idx.Insert(far)
+
+	tests := []struct {
+		radius float64
+		want   []*Object
+	}{
+		// The nearest corner of near is sqrt(50) away
+		{5, nil},
+		{8, []*Object{near}},
+		{150, []*Object{far, near}},
+	}
+	for _, tt := range tests {
+		got := idx.QueryRadius(95, 95, tt.radius)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("radius %v: got %v, want %v", tt.radius, got, tt.want)
+		}
+	}
+}
+
+func TestObjectIndexLookups(t *testing.T) {
+	door := &Object{ID: 1, Name: "door", Class: "Portal"}
+	backDoor := &Object{ID: 2, Name: "door", Type: "Portal"}
+	chest := &Object{ID: 3, Name: "chest", Class: "Container"}
+	idx := NewObjectIndex(16)
+	for _, o := range []*Object{door, backDoor, chest} {
+		idx.Insert(o)
+	}
+
+	if got := idx.GetObjectByID(3); got != chest {
+		t.Errorf("id 3: got %v, want the chest", got)
+	}
+	if got := idx.GetObjectsByName("door"); !slices.Equal(got, []*Object{door, backDoor}) {
+		t.Errorf("name door: got %v", got)
+	}
+	if got := idx.GetObjectsByClass("Portal"); !slices.Equal(got, []*Object{door, backDoor}) {
+		t.Errorf("class Portal: got %v", got)
+	}
+
+	chest.Name, chest.Class = "open chest", ""
+	idx.Update(chest)
+	if got := idx.GetObjectsByName("chest"); len(got) != 0 {
+		t.Errorf("renamed object still found by its old name: %v", got)
+	}
+	if got := idx.GetObjectsByClass("Container"); len(got) != 0 {
+		t.Errorf("object still found by its old class: %v", got)
+	}
+
+	idx.Remove(door)
+	if got := idx.GetObjectsByName("door"); !slices.Equal(got, []*Object{backDoor}) {
+		t.Errorf("name door after removing: got %v", got)
+	}
+}
@@ -0,0 +1,223 @@
+package tmx
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// DefaultIndexCellTiles is the size of an ObjectIndex cell, in tiles, used by Map.BuildObjectIndex
+// when no cell size is given.
+const DefaultIndexCellTiles = 4
+
+// ObjectIndex is a uniform grid over the objects of a map. It answers rectangle and radius
+// queries using the rotated bounds of each object and keeps lookups by id, name and class.
+//
+// The index stores pointers to the objects it was given but does not watch them: an object that is
+// moved, resized, rotated or renamed keeps its old cells and lookups until Update is called for it.
+type ObjectIndex struct {
+	cellSize float64
+	cells    map[indexCell][]*Object
+	entries  map[*Object]*indexEntry
+	byID     map[ID]*Object
+	byName   map[string][]*Object
+	byClass  map[string][]*Object
+}
+
+type indexCell struct {
+	x int
+	y int
+}
+
+type indexEntry struct {
+	bounds  Rect
+	minCell indexCell
+	maxCell indexCell
+	id      ID
+	name    string
+	class   string
+}
+
+func NewObjectIndex(cellSize float64) *ObjectIndex {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	return &ObjectIndex{
+		cellSize: cellSize,
+		cells:    make(map[indexCell][]*Object),
+		entries:  make(map[*Object]*indexEntry),
+		byID:     make(map[ID]*Object),
+		byName:   make(map[string][]*Object),
+		byClass:  make(map[string][]*Object),
+	}
+}
+
+// BuildObjectIndex indexes every object of every object group in the map.
+// A cellSize of zero uses DefaultIndexCellTiles tiles per cell.
+func (m *Map) BuildObjectIndex(cellSize float64) *ObjectIndex {
+	if cellSize <= 0 {
+		cellSize = float64(max(m.TileWidth, m.TileHeight) * DefaultIndexCellTiles)
+	}
+
+	idx := NewObjectIndex(cellSize)
+	for i := range m.ObjectGroups {
+		og := &m.ObjectGroups[i]
+		for j := range og.Objects {
+			idx.Insert(&og.Objects[j])
+		}
+	}
+	return idx
+}
+
+func (idx *ObjectIndex) Len() int {
+	return len(idx.entries)
+}
+
+// Insert adds the object to the index. Inserting an object that is already indexed updates it.
+func (idx *ObjectIndex) Insert(o *Object) {
+	if _, ok := idx.entries[o]; ok {
+		idx.Update(o)
+		return
+	}
+
+	bounds := o.Bounds()
+	e := &indexEntry{
+		bounds:  bounds,
+		minCell: idx.cellAt(bounds.MinX, bounds.MinY),
+		maxCell: idx.cellAt(bounds.MaxX, bounds.MaxY),
+		id:      o.ID,
+		name:    o.Name,
+		class:   o.ClassName(),
+	}
+	idx.entries[o] = e
+
+	for x := e.minCell.x; x <= e.maxCell.x; x++ {
+		for y := e.minCell.y; y <= e.maxCell.y; y++ {
+			c := indexCell{x, y}
+			idx.cells[c] = append(idx.cells[c], o)
+		}
+	}
+
+	if e.id != 0 {
+		idx.byID[e.id] = o
+	}
+	if e.name != "" {
+		idx.byName[e.name] = append(idx.byName[e.name], o)
+	}
+	if e.class != "" {
+		idx.byClass[e.class] = append(idx.byClass[e.class], o)
+	}
+}
+
+// Remove drops the object from the index.
+func (idx *ObjectIndex) Remove(o *Object) {
+	e, ok := idx.entries[o]
+	if !ok {
+		return
+	}
+	delete(idx.entries, o)
+
+	for x := e.minCell.x; x <= e.maxCell.x; x++ {
+		for y := e.minCell.y; y <= e.maxCell.y; y++ {
+			c := indexCell{x, y}
+			if objects := removeObject(idx.cells[c], o); len(objects) > 0 {
+				idx.cells[c] = objects
+			} else {
+				delete(idx.cells, c)
+			}
+		}
+	}
+
+	if e.id != 0 && idx.byID[e.id] == o {
+		delete(idx.byID, e.id)
+	}
+	if e.name != "" {
+		if objects := removeObject(idx.byName[e.name], o); len(objects) > 0 {
+			idx.byName[e.name] = objects
+		} else {
+			delete(idx.byName, e.name)
+		}
+	}
+	if e.class != "" {
+		if objects := removeObject(idx.byClass[e.class], o); len(objects) > 0 {
+			idx.byClass[e.class] = objects
+		} else {
+			delete(idx.byClass, e.class)
+		}
+	}
+}
+
+// Update re-indexes an object after it has been edited.
+func (idx *ObjectIndex) Update(o *Object) {
+	idx.Remove(o)
+	idx.Insert(o)
+}
+
+func (idx *ObjectIndex) GetObjectByID(id ID) *Object {
+	return idx.byID[id]
+}
+
+func (idx *ObjectIndex) GetObjectsByName(name string) []*Object {
+	return slices.Clone(idx.byName[name])
+}
+
+func (idx *ObjectIndex) GetObjectsByClass(class string) []*Object {
+	return slices.Clone(idx.byClass[class])
+}
+
+// QueryRect returns the objects whose bounds intersect r, ordered by id.
+func (idx *ObjectIndex) QueryRect(r Rect) []*Object {
+	return idx.query(r, func(b Rect) bool {
+		return b.Intersects(r)
+	})
+}
+
+// QueryRadius returns the objects whose bounds are within radius of (x, y), ordered by id.
+func (idx *ObjectIndex) QueryRadius(x, y, radius float64) []*Object {
+	r := Rect{MinX: x - radius, MinY: y - radius, MaxX: x + radius, MaxY: y + radius}
+	return idx.query(r, func(b Rect) bool {
+		dx := x - math.Max(b.MinX, math.Min(x, b.MaxX))
+		dy := y - math.Max(b.MinY, math.Min(y, b.MaxY))
+		return dx*dx+dy*dy <= radius*radius
+	})
+}
+
+func (idx *ObjectIndex) query(r Rect, match func(b Rect) bool) []*Object {
+	minCell := idx.cellAt(r.MinX, r.MinY)
+	maxCell := idx.cellAt(r.MaxX, r.MaxY)
+
+	var result []*Object
+	seen := make(map[*Object]bool)
+	for x := minCell.x; x <= maxCell.x; x++ {
+		for y := minCell.y; y <= maxCell.y; y++ {
+			for _, o := range idx.cells[indexCell{x, y}] {
+				if seen[o] {
+					continue
+				}
+				seen[o] = true
+				if match(idx.entries[o].bounds) {
+					result = append(result, o)
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b *Object) int {
+		return cmp.Compare(idx.entries[a].id, idx.entries[b].id)
+	})
+	return result
+}
+
+func (idx *ObjectIndex) cellAt(x, y float64) indexCell {
+	return indexCell{
+		x: int(math.Floor(x / idx.cellSize)),
+		y: int(math.Floor(y / idx.cellSize)),
+	}
+}
+
+func removeObject(objects []*Object, o *Object) []*Object {
+	if i := slices.Index(objects, o); i >= 0 {
+		return slices.Delete(objects, i, i+1)
+	}
+	return objects
+}
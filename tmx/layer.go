@@ -0,0 +1,367 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"path"
+)
+
+// LayerKind is the kind of a layer in the map.
+type LayerKind int
+
+const (
+	layerKindUnknown LayerKind = iota
+	LayerKindTile
+	LayerKindObject
+	LayerKindImage
+	LayerKindGroup
+)
+
+// LayerBase holds the attributes shared by every kind of layer.
+type LayerBase struct {
+	ID         ID         `xml:"id,attr"`
+	Name       string     `xml:"name,attr"`
+	Class      string     `xml:"class,attr"`
+	OffsetX    int        `xml:"offsetx,attr"`
+	OffsetY    int        `xml:"offsety,attr"`
+	Opacity    float32    `xml:"opacity,attr"`
+	Visible    bool       `xml:"visible,attr"`
+	TintColor  string     `xml:"tintcolor,attr"`
+	Properties Properties `xml:"properties>property"`
+	// Tint is the decoded TintColor, white when the layer is not tinted
+	Tint color.NRGBA `xml:"-"`
+	// Parent is the group containing the layer, nil for top level layers
+	Parent *Group `xml:"-"`
+}
+
+// Tiled leaves out the attributes of layers with default values
+func defaultLayerBase() LayerBase {
+	return LayerBase{
+		Opacity: 1,
+		Visible: true,
+		Tint:    white,
+	}
+}
+
+var white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+// Offset returns the offset of the layer combined with the offsets of its groups.
+func (b *LayerBase) Offset() (int, int) {
+	x, y := b.OffsetX, b.OffsetY
+	if b.Parent != nil {
+		px, py := b.Parent.Offset()
+		x, y = x+px, y+py
+	}
+	return x, y
+}
+
+// Style returns the visibility, opacity and tint the layer is drawn with, combined with its groups.
+// The overrides replace the values of the map for the named layers and groups, they may be nil.
+func (b *LayerBase) Style(overrides LayerOverrides) LayerStyle {
+	s := LayerStyle{
+		Visible: b.Visible,
+		Opacity: b.Opacity,
+		Tint:    b.Tint,
+	}
+	if o, ok := overrides[b.Name]; ok {
+		if o.Visible != nil {
+			s.Visible = *o.Visible
+		}
+		if o.Opacity != nil {
+			s.Opacity = *o.Opacity
+		}
+		if o.Tint != nil {
+			s.Tint = *o.Tint
+		}
+	}
+
+	if b.Parent != nil {
+		s = b.Parent.Style(overrides).combine(s)
+	}
+	return s
+}
+
+func (b *LayerBase) decodeTint() error {
+	if b.TintColor == "" {
+		return nil
+	}
+	c, err := ParseColor(b.TintColor)
+	if err != nil {
+		return fmt.Errorf("layer: %s %w", b.Name, err)
+	}
+	b.Tint = c
+	return nil
+}
+
+// LayerStyle is how a layer is drawn.
+type LayerStyle struct {
+	Visible bool
+	Opacity float32
+	// Tint multiplies the colors of the layer
+	Tint color.NRGBA
+}
+
+// Alpha returns the opacity combined with the alpha of the tint.
+func (s LayerStyle) Alpha() float32 {
+	return s.Opacity * float32(s.Tint.A) / 0xff
+}
+
+// combine returns the style of a child layer of a group with this style.
+func (s LayerStyle) combine(child LayerStyle) LayerStyle {
+	return LayerStyle{
+		Visible: s.Visible && child.Visible,
+		Opacity: s.Opacity * child.Opacity,
+		Tint: color.NRGBA{
+			R: uint8(uint32(s.Tint.R) * uint32(child.Tint.R) / 0xff),
+			G: uint8(uint32(s.Tint.G) * uint32(child.Tint.G) / 0xff),
+			B: uint8(uint32(s.Tint.B) * uint32(child.Tint.B) / 0xff),
+			A: uint8(uint32(s.Tint.A) * uint32(child.Tint.A) / 0xff),
+		},
+	}
+}
+
+// LayerOverride changes how a layer or group is drawn at runtime, eg. to fade a roof when the player is
+// inside a building. Nil fields keep the values of the map.
+type LayerOverride struct {
+	Visible *bool
+	Opacity *float32
+	Tint    *color.NRGBA
+}
+
+// LayerOverrides are the overrides of layers and groups by name.
+type LayerOverrides map[string]LayerOverride
+
+func (lo LayerOverrides) SetVisible(name string, visible bool) {
+	o := lo[name]
+	o.Visible = &visible
+	lo[name] = o
+}
+
+func (lo LayerOverrides) SetOpacity(name string, opacity float32) {
+	o := lo[name]
+	o.Opacity = &opacity
+	lo[name] = o
+}
+
+func (lo LayerOverrides) SetTint(name string, tint color.Color) {
+	o := lo[name]
+	c := color.NRGBAModel.Convert(tint).(color.NRGBA)
+	o.Tint = &c
+	lo[name] = o
+}
+
+// Clear removes the overrides of the named layer or group.
+func (lo LayerOverrides) Clear(name string) {
+	delete(lo, name)
+}
+
+// ImageLayer draws a single image.
+type ImageLayer struct {
+	LayerBase
+	RepeatX bool  `xml:"repeatx,attr"`
+	RepeatY bool  `xml:"repeaty,attr"`
+	Image   Image `xml:"image"`
+}
+
+type Image struct {
+	// Source is relative to the working directory once the map is loaded
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+// Group contains layers of any kind, its visibility, opacity, tint and offset apply to all of them.
+type Group struct {
+	LayerBase
+	Children []LayerNode `xml:",any"`
+}
+
+// LayerNode is a layer of any kind, the field of its kind is set.
+type LayerNode struct {
+	Kind        LayerKind
+	Layer       *Layer
+	ObjectGroup *ObjectGroup
+	ImageLayer  *ImageLayer
+	Group       *Group
+}
+
+// Base returns the attributes shared by every kind of layer.
+func (n *LayerNode) Base() *LayerBase {
+	switch n.Kind {
+	case LayerKindTile:
+		return &n.Layer.LayerBase
+	case LayerKindObject:
+		return &n.ObjectGroup.LayerBase
+	case LayerKindImage:
+		return &n.ImageLayer.LayerBase
+	case LayerKindGroup:
+		return &n.Group.LayerBase
+	}
+	return nil
+}
+
+func (n *LayerNode) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	switch start.Name.Local {
+	case "layer":
+		n.Kind, n.Layer = LayerKindTile, &Layer{}
+		return d.DecodeElement(n.Layer, &start)
+	case "objectgroup":
+		n.Kind, n.ObjectGroup = LayerKindObject, &ObjectGroup{}
+		return d.DecodeElement(n.ObjectGroup, &start)
+	case "imagelayer":
+		n.Kind, n.ImageLayer = LayerKindImage, &ImageLayer{}
+		return d.DecodeElement(n.ImageLayer, &start)
+	case "group":
+		n.Kind, n.Group = LayerKindGroup, &Group{}
+		return d.DecodeElement(n.Group, &start)
+	}
+	// Other elements, eg. editorsettings, are dropped once the map is decoded
+	return d.Skip()
+}
+
+func (l *Layer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type layer Layer
+	v := layer{LayerBase: defaultLayerBase()}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*l = Layer(v)
+	return nil
+}
+
+func (og *ObjectGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type objectGroup ObjectGroup
+	v := objectGroup{LayerBase: defaultLayerBase()}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*og = ObjectGroup(v)
+	return nil
+}
+
+func (il *ImageLayer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type imageLayer ImageLayer
+	v := imageLayer{LayerBase: defaultLayerBase()}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*il = ImageLayer(v)
+	return nil
+}
+
+func (g *Group) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type group Group
+	v := group{LayerBase: defaultLayerBase()}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*g = Group(v)
+	return nil
+}
+
+// Objects are visible unless the file says otherwise
+func (o *Object) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type object Object
+	v := object{Visible: true}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*o = Object(v)
+	return nil
+}
+
+// FindLayer returns the named layer of any kind, searching groups in draw order.
+func (m *Map) FindLayer(name string) (*LayerNode, error) {
+	if n := findLayer(m.Children, name); n != nil {
+		return n, nil
+	}
+	return nil, fmt.Errorf("layer: %s %w", name, ErrLayerNotFound)
+}
+
+func findLayer(nodes []LayerNode, name string) *LayerNode {
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Base().Name == name {
+			return n
+		}
+		if n.Kind == LayerKindGroup {
+			if found := findLayer(n.Group.Children, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// decodeChildren collects the layers of each kind, including those nested in groups, in draw order.
+// The nodes and parents point into the slices of the map.
+func (m *Map) decodeChildren() error {
+	type ref struct {
+		node   *LayerNode
+		index  int
+		parent int
+	}
+	var refs []ref
+
+	var walk func(nodes []LayerNode, parent int) []LayerNode
+	walk = func(nodes []LayerNode, parent int) []LayerNode {
+		kept := nodes[:0]
+		for _, n := range nodes {
+			if n.Kind != layerKindUnknown {
+				kept = append(kept, n)
+			}
+		}
+
+		for i := range kept {
+			n := &kept[i]
+			switch n.Kind {
+			case LayerKindTile:
+				refs = append(refs, ref{n, len(m.Layers), parent})
+				m.Layers = append(m.Layers, *n.Layer)
+			case LayerKindObject:
+				refs = append(refs, ref{n, len(m.ObjectGroups), parent})
+				m.ObjectGroups = append(m.ObjectGroups, *n.ObjectGroup)
+			case LayerKindImage:
+				refs = append(refs, ref{n, len(m.ImageLayers), parent})
+				if n.ImageLayer.Image.Source != "" {
+					n.ImageLayer.Image.Source = path.Join(m.baseDir, n.ImageLayer.Image.Source)
+				}
+				m.ImageLayers = append(m.ImageLayers, *n.ImageLayer)
+			case LayerKindGroup:
+				idx := len(m.Groups)
+				refs = append(refs, ref{n, idx, parent})
+				m.Groups = append(m.Groups, *n.Group)
+				children := walk(n.Group.Children, idx)
+				m.Groups[idx].Children = children
+			}
+		}
+		return kept
+	}
+	m.Children = walk(m.Children, -1)
+
+	for _, r := range refs {
+		var base *LayerBase
+		switch r.node.Kind {
+		case LayerKindTile:
+			r.node.Layer = &m.Layers[r.index]
+			base = &r.node.Layer.LayerBase
+		case LayerKindObject:
+			r.node.ObjectGroup = &m.ObjectGroups[r.index]
+			base = &r.node.ObjectGroup.LayerBase
+		case LayerKindImage:
+			r.node.ImageLayer = &m.ImageLayers[r.index]
+			base = &r.node.ImageLayer.LayerBase
+		case LayerKindGroup:
+			r.node.Group = &m.Groups[r.index]
+			base = &r.node.Group.LayerBase
+		}
+		if r.parent >= 0 {
+			base.Parent = &m.Groups[r.parent]
+		}
+		if err := base.decodeTint(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,7 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/internal/pathutil"
 	"github.com/talvor/tiled/tmx"
 )
 
@@ -41,7 +41,7 @@ func NewManager(baseDirs []string) *MapManager {
 
 	// Load maps from the base directories
 	for _, baseDir := range baseDirs {
-		if err := common.PathShouldBeDirectory(baseDir); err != nil {
+		if err := pathutil.PathShouldBeDirectory(baseDir); err != nil {
 			fmt.Printf("Error: %s %v\n", baseDir, err)
 			continue
 		}
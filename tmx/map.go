@@ -8,6 +8,7 @@ import (
 	"errors"
 	"image"
 	"io"
+	"math"
 	"path"
 	"strconv"
 	"strings"
@@ -36,19 +37,25 @@ type (
 
 // All structs have their fields exported, and you'll be on the safe side as long as treat them read-only (anyone want to write 100 getters?).
 type Map struct {
-	baseDir      string
-	Source       string
-	Version      string        `xml:"title,attr"`
-	Class        string        `xml:"class,attr"`
-	Orientation  string        `xml:"orientation,attr"`
-	Width        int           `xml:"width,attr"`
-	Height       int           `xml:"height,attr"`
-	TileWidth    int           `xml:"tilewidth,attr"`
-	TileHeight   int           `xml:"tileheight,attr"`
-	Properties   []Property    `xml:"properties>property"`
-	Tilesets     []Tileset     `xml:"tileset"`
-	Layers       []Layer       `xml:"layer"`
-	ObjectGroups []ObjectGroup `xml:"objectgroup"`
+	baseDir     string
+	templates   map[string]*Template
+	Source      string
+	Version     string     `xml:"title,attr"`
+	Class       string     `xml:"class,attr"`
+	Orientation string     `xml:"orientation,attr"`
+	Width       int        `xml:"width,attr"`
+	Height      int        `xml:"height,attr"`
+	TileWidth   int        `xml:"tilewidth,attr"`
+	TileHeight  int        `xml:"tileheight,attr"`
+	Properties  Properties `xml:"properties>property"`
+	Tilesets    []Tileset  `xml:"tileset"`
+	// Children are the top level layers of every kind in draw order
+	Children []LayerNode `xml:",any"`
+	// Layers of each kind, including those nested in groups, in draw order
+	Layers       []Layer       `xml:"-"`
+	ObjectGroups []ObjectGroup `xml:"-"`
+	ImageLayers  []ImageLayer  `xml:"-"`
+	Groups       []Group       `xml:"-"`
 }
 
 func (m *Map) GetLayer(name string) (*Layer, error) {
@@ -60,7 +67,11 @@ func (m *Map) GetLayer(name string) (*Layer, error) {
 	return nil, ErrLayerNotFound
 }
 
+// DecodeTileGID returns the tileset of a GID and the tile id within it. The flip flags of the GID are ignored.
 func (m *Map) DecodeTileGID(gid GID) (*Tileset, GID) {
+	gid &= GIDMask
+
+	// Tilesets are sorted by descending first GID, the tile belongs to the first one starting before it
 	for i := range m.Tilesets {
 		ts := &m.Tilesets[i]
 		if gid >= ts.FirstGID {
@@ -70,39 +81,36 @@ func (m *Map) DecodeTileGID(gid GID) (*Tileset, GID) {
 	return nil, 0
 }
 
+// Flips returns the flip flags of a GID.
+func (gid GID) Flips() (horizontal bool, vertical bool, diagonal bool) {
+	return gid&GIDHorizontalFlip != 0, gid&GIDVerticalFlip != 0, gid&GIDDiagonalFlip != 0
+}
+
 type Tileset struct {
 	FirstGID GID    `xml:"firstgid,attr"`
 	Source   string `xml:"source,attr"`
 }
 
 type Layer struct {
-	Name       string     `xml:"name,attr"`
-	Width      int        `xml:"width,attr"`
-	Height     int        `xml:"height,attr"`
-	OffsetX    int        `xml:"offsetx,attr"`
-	OffsetY    int        `xml:"offsety,attr"`
-	Opacity    float32    `xml:"opacity,attr"`
-	Visible    bool       `xml:"visible,attr"`
-	Properties []Property `xml:"properties>property"`
-	Data       *Data      `xml:"data"`
-	Tiles      []GID
-	Empty      bool // Set when all entries of the layer are NilTile
+	LayerBase
+	Width  int   `xml:"width,attr"`
+	Height int   `xml:"height,attr"`
+	Data   *Data `xml:"data"`
+	Tiles  []GID
+	Empty  bool // Set when all entries of the layer are NilTile
 }
 
+// GetTilePositionFromIndex returns the position of a tile in the map, including the offsets of the layer and its groups.
 func (l *Layer) GetTilePositionFromIndex(tileIdx int, m *Map) (int, int) {
 	x := tileIdx % l.Width
 	y := tileIdx / l.Width
-	return l.OffsetX + x*m.TileWidth, l.OffsetY + y*m.TileHeight
+	offsetX, offsetY := l.Offset()
+	return offsetX + x*m.TileWidth, offsetY + y*m.TileHeight
 }
 
 func (l *Layer) GetTileRectFromIndex(tileIdx int, m *Map) image.Rectangle {
-	x := tileIdx % l.Width
-	y := tileIdx / l.Width
-	rect := image.Rect(x*m.TileWidth+l.OffsetX,
-		y*m.TileHeight+l.OffsetY,
-		(x+1)*m.TileWidth+l.OffsetX,
-		(y+1)*m.TileHeight+l.OffsetY)
-	return rect
+	x, y := l.GetTilePositionFromIndex(tileIdx, m)
+	return image.Rect(x, y, x+m.TileWidth, y+m.TileHeight)
 }
 
 type Data struct {
@@ -113,26 +121,27 @@ type Data struct {
 }
 
 type ObjectGroup struct {
-	Name       string     `xml:"name,attr"`
-	Color      string     `xml:"color,attr"`
-	Opacity    float32    `xml:"opacity,attr"`
-	Visible    bool       `xml:"visible,attr"`
-	Properties []Property `xml:"properties>property"`
-	Objects    []Object   `xml:"object"`
+	LayerBase
+	Color   string   `xml:"color,attr"`
+	Objects []Object `xml:"object"`
 }
 
 type Object struct {
+	ID         ID         `xml:"id,attr"`
 	Name       string     `xml:"name,attr"`
 	Type       string     `xml:"type,attr"`
+	Class      string     `xml:"class,attr"`
 	X          float64    `xml:"x,attr"`
 	Y          float64    `xml:"y,attr"`
 	Width      float64    `xml:"width,attr"`
 	Height     float64    `xml:"height,attr"`
+	Rotation   float64    `xml:"rotation,attr"`
 	GID        int        `xml:"gid,attr"`
 	Visible    bool       `xml:"visible,attr"`
+	Template   string     `xml:"template,attr"`
 	Polygons   []Polygon  `xml:"polygon"`
 	PolyLines  []PolyLine `xml:"polyline"`
-	Properties []Property `xml:"properties>property"`
+	Properties Properties `xml:"properties>property"`
 }
 
 type Polygon struct {
@@ -143,11 +152,6 @@ type PolyLine struct {
 	Points string `xml:"points,attr"`
 }
 
-type Property struct {
-	Name  string `xml:"name,attr"`
-	Value string `xml:"value,attr"`
-}
-
 func (d *Data) decodeBase64() (data []byte, err error) {
 	rawData := bytes.TrimSpace(d.RawData)
 	r := bytes.NewReader(rawData)
@@ -294,37 +298,69 @@ type Point struct {
 	Y int
 }
 
+// FloatPoint is a point of a polygon or polyline, relative to the position of its object, with the
+// fractional coordinates written by Tiled for shapes that were not snapped to the grid.
+type FloatPoint struct {
+	X float64
+	Y float64
+}
+
 type DataTile struct {
 	GID GID `xml:"gid,attr"`
 }
 
+// Decode returns the points of the polygon rounded to whole pixels, see DecodeFloat.
 func (p *Polygon) Decode() ([]Point, error) {
+	return roundPoints(decodePoints(p.Points))
+}
+
+// DecodeFloat returns the points of the polygon.
+func (p *Polygon) DecodeFloat() ([]FloatPoint, error) {
 	return decodePoints(p.Points)
 }
 
+// Decode returns the points of the polyline rounded to whole pixels, see DecodeFloat.
 func (p *PolyLine) Decode() ([]Point, error) {
+	return roundPoints(decodePoints(p.Points))
+}
+
+// DecodeFloat returns the points of the polyline.
+func (p *PolyLine) DecodeFloat() ([]FloatPoint, error) {
 	return decodePoints(p.Points)
 }
 
-func decodePoints(s string) (points []Point, err error) {
-	pointStrings := strings.Split(s, " ")
+func decodePoints(s string) (points []FloatPoint, err error) {
+	pointStrings := strings.Fields(s)
 
-	points = make([]Point, len(pointStrings))
+	points = make([]FloatPoint, len(pointStrings))
 	for i, pointString := range pointStrings {
 		coordStrings := strings.Split(pointString, ",")
 		if len(coordStrings) != 2 {
-			return []Point{}, ErrInvalidPointsField
+			return []FloatPoint{}, ErrInvalidPointsField
 		}
 
-		points[i].X, err = strconv.Atoi(coordStrings[0])
+		// Tiled writes fractional coordinates for shapes that were not snapped to the grid
+		points[i].X, err = strconv.ParseFloat(coordStrings[0], 64)
 		if err != nil {
-			return []Point{}, err
+			return []FloatPoint{}, err
 		}
 
-		points[i].Y, err = strconv.Atoi(coordStrings[1])
+		points[i].Y, err = strconv.ParseFloat(coordStrings[1], 64)
 		if err != nil {
-			return []Point{}, err
+			return []FloatPoint{}, err
 		}
 	}
 	return
 }
+
+func roundPoints(fp []FloatPoint, err error) ([]Point, error) {
+	if err != nil {
+		return []Point{}, err
+	}
+
+	points := make([]Point, len(fp))
+	for i, p := range fp {
+		points[i] = Point{X: int(math.Round(p.X)), Y: int(math.Round(p.Y))}
+	}
+	return points, nil
+}
@@ -0,0 +1,50 @@
+package tmx
+
+import (
+	"strings"
+	"testing"
+)
+
+const multipleTilesets = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" source="terrain.tsx"/>
+ <tileset firstgid="65" source="props.tsx"/>
+ <tileset firstgid="33" source="water.tsx"/>
+ <layer id="1" name="ground" width="1" height="1">
+  <data encoding="csv">1</data>
+ </layer>
+</map>`
+
+func TestDecodeTileGIDMultipleTilesets(t *testing.T) {
+	m, err := tmxReader("maps/test.tmx", strings.NewReader(multipleTilesets))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		gid    GID
+		source string
+		id     GID
+	}{
+		{1, "maps/terrain.tsx", 0},
+		{32, "maps/terrain.tsx", 31},
+		{33, "maps/water.tsx", 0},
+		{64, "maps/water.tsx", 31},
+		{65, "maps/props.tsx", 0},
+		{70 | GIDHorizontalFlip | GIDDiagonalFlip, "maps/props.tsx", 5},
+	}
+	for _, tt := range tests {
+		ts, id := m.DecodeTileGID(tt.gid)
+		if ts == nil {
+			t.Errorf("gid %d: no tileset", tt.gid&GIDMask)
+			continue
+		}
+		if ts.Source != tt.source || id != tt.id {
+			t.Errorf("gid %d: got %s %d, want %s %d", tt.gid&GIDMask, ts.Source, id, tt.source, tt.id)
+		}
+	}
+
+	if ts, _ := m.DecodeTileGID(0); ts != nil {
+		t.Errorf("empty tile decoded to %s", ts.Source)
+	}
+}
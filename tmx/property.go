@@ -0,0 +1,125 @@
+package tmx
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPropertyNotFound     = errors.New("tmx: property not found")
+	ErrInvalidPropertyValue = errors.New("tmx: invalid property value")
+	ErrInvalidColor         = errors.New("tmx: invalid color")
+)
+
+// Property types written by Tiled in the type attribute. An empty type is a string.
+const (
+	PropertyTypeString = "string"
+	PropertyTypeInt    = "int"
+	PropertyTypeFloat  = "float"
+	PropertyTypeBool   = "bool"
+	PropertyTypeColor  = "color"
+	PropertyTypeFile   = "file"
+	PropertyTypeObject = "object"
+)
+
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Decode converts the property value according to its type.
+// The result is a string, int, float64, bool, color.NRGBA or ID (for object references).
+func (p *Property) Decode() (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch p.Type {
+	case "", PropertyTypeString, PropertyTypeFile:
+		return p.Value, nil
+	case PropertyTypeInt:
+		v, err = strconv.Atoi(p.Value)
+	case PropertyTypeFloat:
+		v, err = strconv.ParseFloat(p.Value, 64)
+	case PropertyTypeBool:
+		v, err = strconv.ParseBool(p.Value)
+	case PropertyTypeColor:
+		v, err = ParseColor(p.Value)
+	case PropertyTypeObject:
+		var id uint64
+		id, err = strconv.ParseUint(p.Value, 10, 32)
+		v = ID(id)
+	default:
+		return p.Value, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("property: %s type: %s value: %q %w", p.Name, p.Type, p.Value, ErrInvalidPropertyValue)
+	}
+	return v, nil
+}
+
+type Properties []Property
+
+func (ps Properties) Get(name string) (*Property, error) {
+	for i := range ps {
+		if ps[i].Name == name {
+			return &ps[i], nil
+		}
+	}
+	return nil, fmt.Errorf("property: %s %w", name, ErrPropertyNotFound)
+}
+
+func (ps Properties) Has(name string) bool {
+	_, err := ps.Get(name)
+	return err == nil
+}
+
+// Decode decodes every property into a map keyed by property name.
+func (ps Properties) Decode() (map[string]any, error) {
+	values := make(map[string]any, len(ps))
+	for i := range ps {
+		v, err := ps[i].Decode()
+		if err != nil {
+			return nil, err
+		}
+		values[ps[i].Name] = v
+	}
+	return values, nil
+}
+
+// merge returns the properties of base overridden by the properties in ps.
+func (ps Properties) merge(base Properties) Properties {
+	merged := make(Properties, 0, len(base)+len(ps))
+	for _, p := range base {
+		if !ps.Has(p.Name) {
+			merged = append(merged, p)
+		}
+	}
+	return append(merged, ps...)
+}
+
+// ParseColor parses a Tiled color in the #RRGGBB or #AARRGGBB format.
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if hex == "" {
+		// Tiled writes an empty value for unset color properties
+		return color.NRGBA{}, nil
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("color: %q %w", s, ErrInvalidColor)
+	}
+
+	switch len(hex) {
+	case 6:
+		return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+	case 8:
+		return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: uint8(v >> 24)}, nil
+	}
+	return color.NRGBA{}, fmt.Errorf("color: %q %w", s, ErrInvalidColor)
+}
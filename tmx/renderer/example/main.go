@@ -20,6 +20,10 @@ func init() {
 
 	tsxr := tsxrenderer.NewRenderer(tm)
 	tmxr = tmxrenderer.NewRenderer(mm, tsxr)
+
+	// The building layers are hidden in the map file
+	tmxr.LayerOverrides("StartScene").SetVisible("bottom", true)
+	tmxr.LayerOverrides("StartScene").SetVisible("top", true)
 }
 
 type Game struct{}
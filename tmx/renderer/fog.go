@@ -0,0 +1,95 @@
+package renderer
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/tmx/fov"
+	tsxrenderer "github.com/talvor/tiled/tsx/renderer"
+)
+
+// FogOfWar remembers the tiles that have been seen and darkens the rest of the map.
+// Tiles never seen are drawn with UnexploredColor, tiles seen before but not visible now with ExploredColor.
+type FogOfWar struct {
+	Explored        *fov.Visibility
+	Visible         *fov.Visibility
+	UnexploredColor color.Color
+	ExploredColor   color.Color
+}
+
+func NewFogOfWar(width int, height int) *FogOfWar {
+	return &FogOfWar{
+		Explored:        fov.NewVisibility(width, height),
+		Visible:         fov.NewVisibility(width, height),
+		UnexploredColor: color.Black,
+		ExploredColor:   color.NRGBA{A: 0x80},
+	}
+}
+
+// Update sets the currently visible tiles and adds them to the explored tiles.
+func (f *FogOfWar) Update(vis *fov.Visibility) {
+	f.Visible = vis
+	f.Explored.Merge(vis)
+}
+
+func (f *FogOfWar) tileColor(x, y int) color.Color {
+	switch {
+	case f.Visible.Visible(x, y):
+		return nil
+	case f.Explored.Visible(x, y):
+		return f.ExploredColor
+	}
+	return f.UnexploredColor
+}
+
+// DrawFogOfWar draws the fog over the map. Runs of tiles in the same state are drawn as a single rectangle.
+func (r *Renderer) DrawFogOfWar(mapName string, fog *FogOfWar, opts *common.DrawOptions) error {
+	m, err := r.MapManager.GetMapByName(mapName)
+	if err != nil {
+		return err
+	}
+
+	// The fog covers tiles that may still be queued in a batch
+	r.TsxRenderer.Flush()
+
+	if r.fogImage == nil {
+		r.fogImage = ebiten.NewImage(1, 1)
+		r.fogImage.Fill(color.White)
+	}
+
+	drawRun := func(c color.Color, x, y, length int) {
+		if c == nil {
+			return
+		}
+		op := &ebiten.DrawImageOptions{}
+		// The camera applies to the run before it is placed on the map, like the tiles
+		op.GeoM.Scale(float64(length*m.TileWidth), float64(m.TileHeight))
+		op.GeoM.Concat(opts.Op.GeoM)
+		op.GeoM.Translate(float64(x*m.TileWidth), float64(y*m.TileHeight))
+		if r.TsxRenderer.SnapPixels {
+			// Keep the edges of the fog on the edges of the snapped tiles
+			tsxrenderer.SnapGeoM(&op.GeoM, 1, 1)
+		}
+		op.ColorScale.ScaleWithColor(c)
+		opts.Screen.DrawImage(r.fogImage, op)
+	}
+
+	for y := range m.Height {
+		start := 0
+		current := fog.tileColor(0, y)
+		for x := 1; x <= m.Width; x++ {
+			var c color.Color
+			if x < m.Width {
+				c = fog.tileColor(x, y)
+				if c == current {
+					continue
+				}
+			}
+			drawRun(current, start, y, x-start)
+			start = x
+			current = c
+		}
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package renderer
 import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/render"
+	"github.com/talvor/tiled/tmx"
 	"github.com/talvor/tiled/tmx/manager"
 	tsxrenderer "github.com/talvor/tiled/tsx/renderer"
 )
@@ -10,44 +12,74 @@ import (
 type Renderer struct {
 	TsxRenderer *tsxrenderer.Renderer
 	MapManager  *manager.MapManager
+	fogImage    *ebiten.Image
+	overrides   map[string]tmx.LayerOverrides
 }
 
 func NewRenderer(mm *manager.MapManager, tsxRenderer *tsxrenderer.Renderer) *Renderer {
 	return &Renderer{
 		TsxRenderer: tsxRenderer,
 		MapManager:  mm,
+		overrides:   make(map[string]tmx.LayerOverrides),
 	}
 }
 
-func (r *Renderer) DrawMapLayer(mapName string, layerName string, opts *common.DrawOptions) error {
+// LayerOverrides returns the runtime overrides of the layers of the named map, eg. to fade a roof
+// when the player walks inside a building.
+//
+//	tmxr.LayerOverrides("town").SetOpacity("roof", 0.3)
+func (r *Renderer) LayerOverrides(mapName string) tmx.LayerOverrides {
+	lo, ok := r.overrides[mapName]
+	if !ok {
+		lo = make(tmx.LayerOverrides)
+		r.overrides[mapName] = lo
+	}
+	return lo
+}
+
+// DrawMap draws every layer of the map in order.
+func (r *Renderer) DrawMap(mapName string, opts *common.DrawOptions) error {
 	m, err := r.MapManager.GetMapByName(mapName)
 	if err != nil {
 		return err
 	}
 
-	layer, err := m.GetLayer(layerName)
+	if !r.TsxRenderer.Batching() {
+		r.TsxRenderer.BeginBatch()
+		defer r.TsxRenderer.EndBatch()
+	}
+
+	mr, op := r.mapRenderer(mapName, opts)
+	return mr.DrawMap(m, op)
+}
+
+// DrawMapLayer draws the named layer of the map, a group draws all of its layers. Layers are drawn with
+// their visibility, opacity and tint combined with those of their groups and the layer overrides.
+func (r *Renderer) DrawMapLayer(mapName string, layerName string, opts *common.DrawOptions) error {
+	m, err := r.MapManager.GetMapByName(mapName)
 	if err != nil {
 		return err
 	}
 
-	for idx, tileId := range layer.Tiles {
-		ts, id := m.DecodeTileGID(tileId)
-		if ts == nil {
-			continue
-		}
+	// Draw the layer with a draw call per tileset image, unless the caller is already batching
+	if !r.TsxRenderer.Batching() {
+		r.TsxRenderer.BeginBatch()
+		defer r.TsxRenderer.EndBatch()
+	}
 
-		posX, posY := layer.GetTilePositionFromIndex(idx, m)
+	mr, op := r.mapRenderer(mapName, opts)
+	return mr.DrawMapLayer(m, layerName, op)
+}
 
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Concat(opts.Op.GeoM)
-		op.GeoM.Translate(float64(posX), float64(posY))
+// mapRenderer returns the renderer drawing the layers of the named map to the screen of opts, through
+// the canvas of the tileset renderer, and the options of the camera.
+func (r *Renderer) mapRenderer(mapName string, opts *common.DrawOptions) (*render.Renderer, *render.Options) {
+	canvas := r.TsxRenderer.NewCanvas(opts.Screen)
+	canvas.Filter = opts.Op.Filter
+	canvas.Blend = opts.Op.Blend
 
-		if err := r.TsxRenderer.DrawTileWithSource(ts.Source, uint32(id), &common.DrawOptions{
-			Screen: opts.Screen,
-			Op:     op,
-		}); err != nil {
-			return err
-		}
-	}
-	return nil
+	mr := render.NewRenderer(canvas, r.TsxRenderer.TilesetManager)
+	mr.SetClock(r.TsxRenderer.Clock)
+	mr.LayerOverrides = r.overrides[mapName]
+	return mr, tsxrenderer.RenderOptions(opts.Op)
 }
@@ -0,0 +1,258 @@
+package spawner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/talvor/tiled/tmx"
+)
+
+var (
+	ErrUnknownClass    = errors.New("spawner: unknown class")
+	ErrMissingProperty = errors.New("spawner: missing required property")
+	ErrPropertyType    = errors.New("spawner: property has wrong type")
+	ErrTemplate        = errors.New("spawner: failed to resolve template")
+	ErrConstructor     = errors.New("spawner: constructor failed")
+)
+
+// Object is the decoded form of a tmx.Object that is handed to a Constructor.
+// Templates have been applied and property values converted to their Tiled types.
+type Object struct {
+	ID         tmx.ID
+	Name       string
+	Class      string
+	Group      string
+	X          float64
+	Y          float64
+	Width      float64
+	Height     float64
+	Rotation   float64
+	GID        tmx.GID
+	Properties map[string]any
+	Source     *tmx.Object
+}
+
+func (o *Object) GetString(name string) string {
+	v, _ := o.Properties[name].(string)
+	return v
+}
+
+func (o *Object) GetInt(name string) int {
+	v, _ := o.Properties[name].(int)
+	return v
+}
+
+func (o *Object) GetFloat(name string) float64 {
+	switch v := o.Properties[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+func (o *Object) GetBool(name string) bool {
+	v, _ := o.Properties[name].(bool)
+	return v
+}
+
+// Constructor builds a game entity from a decoded object.
+type Constructor func(obj *Object) (any, error)
+
+// PropertySpec describes a property a class expects. An empty Type accepts any type.
+type PropertySpec struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+type registration struct {
+	constructor Constructor
+	properties  []PropertySpec
+}
+
+// Registry maps object classes to the constructors that build them.
+type Registry struct {
+	registrations map[string]*registration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		registrations: make(map[string]*registration),
+	}
+}
+
+// Register adds a constructor for objects of the given class. Registering a class twice replaces the
+// previous constructor.
+func (r *Registry) Register(class string, constructor Constructor, properties ...PropertySpec) {
+	r.registrations[class] = &registration{
+		constructor: constructor,
+		properties:  properties,
+	}
+}
+
+func (r *Registry) Has(class string) bool {
+	_, ok := r.registrations[class]
+	return ok
+}
+
+// SpawnError describes why an object could not be spawned.
+type SpawnError struct {
+	Group    string
+	ObjectID tmx.ID
+	Name     string
+	Class    string
+	Err      error
+}
+
+func (e *SpawnError) Error() string {
+	return fmt.Sprintf("group:%s object:%d name:%s class:%s %v", e.Group, e.ObjectID, e.Name, e.Class, e.Err)
+}
+
+func (e *SpawnError) Unwrap() error {
+	return e.Err
+}
+
+// Spawner walks the object groups of a map and builds entities with the constructors in its registry.
+type Spawner struct {
+	Registry *Registry
+	// IgnoreClasses lists classes that are used in the map but are not entities, eg. triggers.
+	IgnoreClasses map[string]bool
+}
+
+func NewSpawner(registry *Registry) *Spawner {
+	return &Spawner{
+		Registry:      registry,
+		IgnoreClasses: make(map[string]bool),
+	}
+}
+
+func (s *Spawner) Ignore(classes ...string) {
+	for _, class := range classes {
+		s.IgnoreClasses[class] = true
+	}
+}
+
+// Spawn builds an entity for every object with a class in every object group of the map.
+// Objects that fail are skipped and their errors are returned together as a single error,
+// each of them a *SpawnError.
+func (s *Spawner) Spawn(m *tmx.Map) ([]any, error) {
+	var (
+		entities []any
+		errs     []error
+	)
+	for i := range m.ObjectGroups {
+		e, err := s.spawnGroup(m, &m.ObjectGroups[i])
+		entities = append(entities, e...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return entities, errors.Join(errs...)
+}
+
+// SpawnGroup builds the entities of a single object group.
+func (s *Spawner) SpawnGroup(m *tmx.Map, groupName string) ([]any, error) {
+	for i := range m.ObjectGroups {
+		if m.ObjectGroups[i].Name == groupName {
+			return s.spawnGroup(m, &m.ObjectGroups[i])
+		}
+	}
+	return nil, fmt.Errorf("group: %s %w", groupName, tmx.ErrLayerNotFound)
+}
+
+func (s *Spawner) spawnGroup(m *tmx.Map, og *tmx.ObjectGroup) ([]any, error) {
+	var (
+		entities []any
+		errs     []error
+	)
+	for i := range og.Objects {
+		o := &og.Objects[i]
+
+		entity, err := s.spawnObject(m, og, o)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if entity != nil {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, errors.Join(errs...)
+}
+
+func (s *Spawner) spawnObject(m *tmx.Map, og *tmx.ObjectGroup, o *tmx.Object) (any, error) {
+	spawnError := func(class string, err error) error {
+		return &SpawnError{Group: og.Name, ObjectID: o.ID, Name: o.Name, Class: class, Err: err}
+	}
+
+	resolved, err := m.ResolveTemplate(o)
+	if err != nil {
+		return nil, spawnError(o.ClassName(), fmt.Errorf("%w: %w", ErrTemplate, err))
+	}
+
+	class := resolved.ClassName()
+	if class == "" || s.IgnoreClasses[class] {
+		return nil, nil
+	}
+
+	reg, ok := s.Registry.registrations[class]
+	if !ok {
+		return nil, spawnError(class, ErrUnknownClass)
+	}
+
+	if err := validateProperties(resolved.Properties, reg.properties); err != nil {
+		return nil, spawnError(class, err)
+	}
+
+	properties, err := resolved.Properties.Decode()
+	if err != nil {
+		return nil, spawnError(class, err)
+	}
+
+	obj := &Object{
+		ID:         resolved.ID,
+		Name:       resolved.Name,
+		Class:      class,
+		Group:      og.Name,
+		X:          resolved.X,
+		Y:          resolved.Y,
+		Width:      resolved.Width,
+		Height:     resolved.Height,
+		Rotation:   resolved.Rotation,
+		GID:        tmx.GID(resolved.GID),
+		Properties: properties,
+		Source:     o,
+	}
+
+	entity, err := reg.constructor(obj)
+	if err != nil {
+		return nil, spawnError(class, fmt.Errorf("%w: %w", ErrConstructor, err))
+	}
+	return entity, nil
+}
+
+func validateProperties(properties tmx.Properties, specs []PropertySpec) error {
+	var errs []error
+	for _, spec := range specs {
+		p, err := properties.Get(spec.Name)
+		if err != nil {
+			if spec.Required {
+				errs = append(errs, fmt.Errorf("property: %s %w", spec.Name, ErrMissingProperty))
+			}
+			continue
+		}
+		if spec.Type != "" && propertyType(p) != spec.Type {
+			errs = append(errs, fmt.Errorf("property: %s expected: %s got: %s %w", spec.Name, spec.Type, propertyType(p), ErrPropertyType))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func propertyType(p *tmx.Property) string {
+	if p.Type == "" {
+		return tmx.PropertyTypeString
+	}
+	return p.Type
+}
@@ -0,0 +1,154 @@
+package spawner
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/talvor/tiled/tmx"
+)
+
+func loadMap(t *testing.T) *tmx.Map {
+	t.Helper()
+	m, err := tmx.LoadFile("testdata/spawn.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// spawnAll returns a registry building every class in classes as its decoded object.
+func spawnAll(classes ...string) *Registry {
+	r := NewRegistry()
+	for _, class := range classes {
+		r.Register(class, func(obj *Object) (any, error) { return obj, nil })
+	}
+	return r
+}
+
+func TestSpawnResolvesTemplates(t *testing.T) {
+	s := NewSpawner(spawnAll("Enemy", "Chest"))
+	s.Ignore("Ghost", "Trigger")
+
+	entities, err := s.SpawnGroup(loadMap(t), "entities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("got %d entities, want 2", len(entities))
+	}
+
+	chest := entities[1].(*Object)
+	if chest.Name != "chest" || chest.Class != "Chest" {
+		t.Errorf("got %s %s, want the name and class of the template", chest.Name, chest.Class)
+	}
+	if chest.X != 80 || chest.Y != 96 || chest.Width != 16 || chest.Height != 16 {
+		t.Errorf("got %v,%v %vx%v, want the position of the object and the size of the template", chest.X, chest.Y, chest.Width, chest.Height)
+	}
+	if got := chest.GetInt("gold"); got != 50 {
+		t.Errorf("gold: got %d, want the value of the object 50", got)
+	}
+	if !chest.GetBool("locked") {
+		t.Error("locked: the property of the template is missing")
+	}
+}
+
+func TestSpawnConvertsProperties(t *testing.T) {
+	s := NewSpawner(spawnAll("Enemy", "Chest"))
+	s.Ignore("Ghost", "Trigger")
+
+	entities, err := s.SpawnGroup(loadMap(t), "entities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard := entities[0].(*Object)
+
+	tests := []struct {
+		name string
+		want any
+	}{
+		{"health", 20},
+		{"speed", 1.5},
+		{"hostile", true},
+		{"tint", color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}},
+		{"target", tmx.ID(2)},
+		{"dialog", "halt"},
+	}
+	for _, tt := range tests {
+		if got := guard.Properties[tt.name]; got != tt.want {
+			t.Errorf("%s: got %v (%T), want %v (%T)", tt.name, got, got, tt.want, tt.want)
+		}
+	}
+	if got := guard.GetFloat("health"); got != 20 {
+		t.Errorf("GetFloat of an int: got %v, want 20", got)
+	}
+}
+
+func TestSpawnReportsErrors(t *testing.T) {
+	s := NewSpawner(spawnAll("Enemy", "Chest"))
+	s.Ignore("Trigger")
+
+	entities, err := s.Spawn(loadMap(t))
+	if len(entities) != 2 {
+		t.Errorf("got %d entities, want the 2 that spawned", len(entities))
+	}
+
+	tests := []struct {
+		id   tmx.ID
+		want error
+	}{
+		{3, ErrUnknownClass},
+		{5, tmx.ErrInvalidPropertyValue},
+	}
+	for _, tt := range tests {
+		if !hasSpawnError(err, tt.id, tt.want) {
+			t.Errorf("object %d: got %v, want %v", tt.id, err, tt.want)
+		}
+	}
+	if hasSpawnError(err, 4, nil) {
+		t.Error("an ignored class was reported")
+	}
+}
+
+func TestSpawnValidatesProperties(t *testing.T) {
+	r := spawnAll("Chest")
+	r.Register("Enemy", func(obj *Object) (any, error) { return obj, nil },
+		PropertySpec{Name: "health", Type: tmx.PropertyTypeFloat},
+		PropertySpec{Name: "armor", Required: true},
+	)
+	s := NewSpawner(r)
+	s.Ignore("Ghost", "Trigger")
+
+	_, err := s.SpawnGroup(loadMap(t), "entities")
+	if !hasSpawnError(err, 1, ErrPropertyType) {
+		t.Errorf("got %v, want %v", err, ErrPropertyType)
+	}
+	if !hasSpawnError(err, 1, ErrMissingProperty) {
+		t.Errorf("got %v, want %v", err, ErrMissingProperty)
+	}
+}
+
+func TestSpawnGroupNotFound(t *testing.T) {
+	s := NewSpawner(NewRegistry())
+	if _, err := s.SpawnGroup(loadMap(t), "missing"); !errors.Is(err, tmx.ErrLayerNotFound) {
+		t.Errorf("got %v, want %v", err, tmx.ErrLayerNotFound)
+	}
+}
+
+// hasSpawnError reports whether err joins a *SpawnError for the object wrapping want, any error when want is nil.
+func hasSpawnError(err error, id tmx.ID, want error) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return false
+	}
+	for _, e := range joined.Unwrap() {
+		if hasSpawnError(e, id, want) {
+			return true
+		}
+		var se *SpawnError
+		if errors.As(e, &se) && se.ObjectID == id && (want == nil || errors.Is(se, want)) {
+			return true
+		}
+	}
+	return false
+}
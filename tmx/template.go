@@ -0,0 +1,120 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+var ErrTemplateTilesetNotFound = errors.New("tmx: template tileset not used by map")
+
+// Template is an object template stored in a TX file.
+type Template struct {
+	Source  string
+	Tileset *Tileset `xml:"tileset"`
+	Object  Object   `xml:"object"`
+}
+
+// LoadTemplate function loads an object template in TX format from file
+func LoadTemplate(fileName string) (*Template, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t := &Template{Source: fileName}
+	if err := xml.NewDecoder(f).Decode(t); err != nil {
+		return nil, err
+	}
+
+	if t.Tileset != nil && t.Tileset.Source != "" {
+		t.Tileset.Source = path.Join(filepath.Dir(fileName), t.Tileset.Source)
+	}
+
+	return t, nil
+}
+
+// ResolveTemplate returns a copy of the object with the attributes of its template applied.
+// Attributes set on the object override the template. Objects without a template are returned as is.
+// Templates are loaded relative to the map and cached on the map.
+func (m *Map) ResolveTemplate(o *Object) (Object, error) {
+	if o.Template == "" {
+		return *o, nil
+	}
+
+	source := path.Join(m.baseDir, o.Template)
+	t, ok := m.templates[source]
+	if !ok {
+		var err error
+		if t, err = LoadTemplate(source); err != nil {
+			return Object{}, fmt.Errorf("template: %s %w", source, err)
+		}
+		if m.templates == nil {
+			m.templates = make(map[string]*Template)
+		}
+		m.templates[source] = t
+	}
+
+	resolved := t.Object
+	resolved.ID = o.ID
+	resolved.X = o.X
+	resolved.Y = o.Y
+	resolved.Visible = o.Visible
+	resolved.Template = o.Template
+	resolved.Properties = o.Properties.merge(t.Object.Properties)
+
+	if o.Name != "" {
+		resolved.Name = o.Name
+	}
+	if o.Type != "" {
+		resolved.Type = o.Type
+	}
+	if o.Class != "" {
+		resolved.Class = o.Class
+	}
+	if o.Width != 0 {
+		resolved.Width = o.Width
+	}
+	if o.Height != 0 {
+		resolved.Height = o.Height
+	}
+	if o.Rotation != 0 {
+		resolved.Rotation = o.Rotation
+	}
+	if len(o.Polygons) > 0 {
+		resolved.Polygons = o.Polygons
+	}
+	if len(o.PolyLines) > 0 {
+		resolved.PolyLines = o.PolyLines
+	}
+
+	switch {
+	case o.GID != 0:
+		resolved.GID = o.GID
+	case t.Object.GID != 0 && t.Tileset != nil:
+		// The template GID is relative to the tileset referenced by the template
+		gid, err := m.templateGID(t)
+		if err != nil {
+			return Object{}, err
+		}
+		resolved.GID = gid
+	}
+
+	return resolved, nil
+}
+
+func (m *Map) templateGID(t *Template) (int, error) {
+	flags := GID(t.Object.GID) & GIDFlip
+	id := GID(t.Object.GID)&GIDMask - t.Tileset.FirstGID
+
+	for _, ts := range m.Tilesets {
+		if ts.Source == t.Tileset.Source {
+			return int((ts.FirstGID + id) | flags), nil
+		}
+	}
+	return 0, fmt.Errorf("template: %s tileset: %s %w", t.Source, t.Tileset.Source, ErrTemplateTilesetNotFound)
+}
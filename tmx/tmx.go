@@ -24,6 +24,10 @@ func tmxReader(source string, r io.Reader) (*Map, error) {
 
 	sort.Slice(m.Tilesets, func(i, j int) bool { return m.Tilesets[i].FirstGID > m.Tilesets[j].FirstGID })
 
+	if err := m.decodeChildren(); err != nil {
+		return nil, err
+	}
+
 	err := m.decodeLayers()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,155 @@
+package trigger
+
+import (
+	"slices"
+
+	"github.com/talvor/tiled/tmx"
+)
+
+// Event is passed to the handlers of a System. Actor is the key the game used to identify the actor.
+type Event[A comparable] struct {
+	Trigger *Trigger
+	Actor   A
+	X       float64
+	Y       float64
+}
+
+type Handler[A comparable] func(e Event[A])
+
+// System tracks actor positions against a set of triggers and fires enter, stay and exit
+// handlers as actors move.
+type System[A comparable] struct {
+	Triggers []*Trigger
+	index    *tmx.ObjectIndex
+	byObject map[*tmx.Object]*Trigger
+	inside   map[A][]*Trigger
+	onEnter  []Handler[A]
+	onStay   []Handler[A]
+	onExit   []Handler[A]
+}
+
+func NewSystem[A comparable](triggers []*Trigger, cellSize float64) *System[A] {
+	s := &System[A]{
+		Triggers: triggers,
+		index:    tmx.NewObjectIndex(cellSize),
+		byObject: make(map[*tmx.Object]*Trigger),
+		inside:   make(map[A][]*Trigger),
+	}
+	for _, t := range triggers {
+		s.index.Insert(&t.Object)
+		s.byObject[&t.Object] = t
+	}
+	return s
+}
+
+// NewSystemFromMap builds a system from the triggers of the map accepted by the filter.
+func NewSystemFromMap[A comparable](m *tmx.Map, filter Filter) (*System[A], error) {
+	triggers, err := FromMap(m, filter)
+	if err != nil {
+		return nil, err
+	}
+	return NewSystem[A](triggers, float64(max(m.TileWidth, m.TileHeight)*tmx.DefaultIndexCellTiles)), nil
+}
+
+// OnEnter registers a handler called when an actor moves into a trigger.
+func (s *System[A]) OnEnter(h Handler[A]) {
+	s.onEnter = append(s.onEnter, h)
+}
+
+// OnStay registers a handler called on every update while an actor remains inside a trigger.
+func (s *System[A]) OnStay(h Handler[A]) {
+	s.onStay = append(s.onStay, h)
+}
+
+// OnExit registers a handler called when an actor leaves a trigger or is removed.
+func (s *System[A]) OnExit(h Handler[A]) {
+	s.onExit = append(s.onExit, h)
+}
+
+// UpdateTrigger re-indexes a trigger after its object has been moved or reshaped.
+func (s *System[A]) UpdateTrigger(t *Trigger) {
+	s.index.Update(&t.Object)
+}
+
+// RemoveTrigger drops a trigger from the system, firing exit handlers for every actor inside it.
+func (s *System[A]) RemoveTrigger(t *Trigger) {
+	if _, ok := s.byObject[&t.Object]; !ok {
+		return
+	}
+	s.index.Remove(&t.Object)
+	delete(s.byObject, &t.Object)
+	s.Triggers = slices.DeleteFunc(slices.Clone(s.Triggers), func(c *Trigger) bool { return c == t })
+
+	for actor, inside := range s.inside {
+		if !containsTrigger(inside, t) {
+			continue
+		}
+		if inside = slices.DeleteFunc(inside, func(c *Trigger) bool { return c == t }); len(inside) > 0 {
+			s.inside[actor] = inside
+		} else {
+			delete(s.inside, actor)
+		}
+		s.fire(s.onExit, Event[A]{Trigger: t, Actor: actor})
+	}
+}
+
+// Update records the position of an actor and fires the handlers for the triggers it entered,
+// stayed in and exited since the previous update. Exits fire before enters.
+func (s *System[A]) Update(actor A, x, y float64) {
+	var current []*Trigger
+	for _, o := range s.index.QueryRadius(x, y, 0) {
+		t := s.byObject[o]
+		if t.Contains(x, y) {
+			current = append(current, t)
+		}
+	}
+
+	previous := s.inside[actor]
+	for _, t := range previous {
+		if !containsTrigger(current, t) {
+			s.fire(s.onExit, Event[A]{Trigger: t, Actor: actor, X: x, Y: y})
+		}
+	}
+	for _, t := range current {
+		e := Event[A]{Trigger: t, Actor: actor, X: x, Y: y}
+		if containsTrigger(previous, t) {
+			s.fire(s.onStay, e)
+		} else {
+			s.fire(s.onEnter, e)
+		}
+	}
+
+	if len(current) > 0 {
+		s.inside[actor] = current
+	} else {
+		delete(s.inside, actor)
+	}
+}
+
+// Remove forgets an actor, firing exit handlers for every trigger it was inside.
+func (s *System[A]) Remove(actor A) {
+	for _, t := range s.inside[actor] {
+		s.fire(s.onExit, Event[A]{Trigger: t, Actor: actor})
+	}
+	delete(s.inside, actor)
+}
+
+// Inside returns the triggers the actor was inside at its last update.
+func (s *System[A]) Inside(actor A) []*Trigger {
+	return slices.Clone(s.inside[actor])
+}
+
+func (s *System[A]) fire(handlers []Handler[A], e Event[A]) {
+	for _, h := range handlers {
+		h(e)
+	}
+}
+
+func containsTrigger(triggers []*Trigger, t *Trigger) bool {
+	for _, c := range triggers {
+		if c == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package trigger
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// recorder collects the events fired by a system as "event actor trigger".
+type recorder []string
+
+func (r *recorder) listen(s *System[string]) {
+	s.OnEnter(func(e Event[string]) { *r = append(*r, fmt.Sprintf("enter %s %s", e.Actor, e.Trigger.Object.Name)) })
+	s.OnStay(func(e Event[string]) { *r = append(*r, fmt.Sprintf("stay %s %s", e.Actor, e.Trigger.Object.Name)) })
+	s.OnExit(func(e Event[string]) { *r = append(*r, fmt.Sprintf("exit %s %s", e.Actor, e.Trigger.Object.Name)) })
+}
+
+// take returns the events since the last call.
+func (r *recorder) take() []string {
+	events := *r
+	*r = nil
+	return events
+}
+
+func newSystem(t *testing.T) (*System[string], *recorder) {
+	t.Helper()
+	s, err := NewSystemFromMap[string](loadMap(t), ByGroup("zones"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &recorder{}
+	r.listen(s)
+	return s, r
+}
+
+func TestSystemEnterStayExit(t *testing.T) {
+	s, r := newSystem(t)
+
+	steps := []struct {
+		x, y float64
+		want []string
+	}{
+		{8, 8, []string{"enter hero inn"}},
+		{10, 10, []string{"stay hero inn"}},
+		// The inn and the cellar overlap from 16,16 to 32,32
+		{24, 24, []string{"stay hero inn", "enter hero cellar"}},
+		{40, 40, []string{"exit hero inn", "stay hero cellar"}},
+		{200, 200, []string{"exit hero cellar"}},
+		{200, 200, nil},
+	}
+	for i, step := range steps {
+		s.Update("hero", step.x, step.y)
+		if got := r.take(); !slices.Equal(got, step.want) {
+			t.Errorf("step %d at %v,%v: got %v, want %v", i, step.x, step.y, got, step.want)
+		}
+	}
+}
+
+func TestSystemRemoveActor(t *testing.T) {
+	s, r := newSystem(t)
+	s.Update("hero", 24, 24)
+	r.take()
+
+	s.Remove("hero")
+	if got, want := r.take(), []string{"exit hero inn", "exit hero cellar"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := s.Inside("hero"); len(got) != 0 {
+		t.Errorf("removed actor is inside %v", names(got))
+	}
+}
+
+func TestSystemRemoveTrigger(t *testing.T) {
+	s, r := newSystem(t)
+	s.Update("hero", 24, 24)
+	s.Update("guard", 8, 8)
+	r.take()
+
+	inn := s.Triggers[0]
+	s.RemoveTrigger(inn)
+	got := r.take()
+	slices.Sort(got)
+	if want := []string{"exit guard inn", "exit hero inn"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := names(s.Triggers); !slices.Equal(got, []string{"cellar", "ramp"}) {
+		t.Errorf("triggers: got %v", got)
+	}
+
+	// The actors do not enter or exit the removed trigger again
+	s.Update("hero", 24, 24)
+	s.Update("guard", 200, 200)
+	if got, want := r.take(), []string{"stay hero cellar"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSystemInsideIsACopy(t *testing.T) {
+	s, r := newSystem(t)
+	s.Update("hero", 24, 24)
+
+	inside := s.Inside("hero")
+	inside[0] = nil
+	if got := s.Inside("hero"); got[0] == nil {
+		t.Fatal("changing the result of Inside changed the system")
+	}
+
+	r.take()
+	s.Update("hero", 24, 24)
+	if got, want := r.take(), []string{"stay hero inn", "stay hero cellar"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
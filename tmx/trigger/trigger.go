@@ -0,0 +1,104 @@
+package trigger
+
+import (
+	"slices"
+
+	"github.com/talvor/tiled/tmx"
+)
+
+// Trigger is a region of the map built from a rectangle or polygon object.
+type Trigger struct {
+	// Object is the trigger object with its template applied
+	Object tmx.Object
+	Group  string
+}
+
+func (t *Trigger) Contains(x, y float64) bool {
+	return t.Object.Contains(x, y)
+}
+
+func (t *Trigger) Class() string {
+	return t.Object.ClassName()
+}
+
+// Filter decides whether an object becomes a trigger.
+type Filter func(og *tmx.ObjectGroup, o *tmx.Object) bool
+
+// ByClass accepts objects with one of the classes.
+func ByClass(classes ...string) Filter {
+	return func(_ *tmx.ObjectGroup, o *tmx.Object) bool {
+		return slices.Contains(classes, o.ClassName())
+	}
+}
+
+// ByGroup accepts objects in one of the named object groups.
+func ByGroup(names ...string) Filter {
+	return func(og *tmx.ObjectGroup, _ *tmx.Object) bool {
+		return slices.Contains(names, og.Name)
+	}
+}
+
+// ByProperty accepts objects with the property set to value. An empty value accepts any value.
+func ByProperty(name string, value string) Filter {
+	return func(_ *tmx.ObjectGroup, o *tmx.Object) bool {
+		p, err := o.Properties.Get(name)
+		if err != nil {
+			return false
+		}
+		return value == "" || p.Value == value
+	}
+}
+
+// All accepts objects accepted by every filter.
+func All(filters ...Filter) Filter {
+	return func(og *tmx.ObjectGroup, o *tmx.Object) bool {
+		for _, f := range filters {
+			if !f(og, o) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any accepts objects accepted by at least one filter.
+func Any(filters ...Filter) Filter {
+	return func(og *tmx.ObjectGroup, o *tmx.Object) bool {
+		for _, f := range filters {
+			if f(og, o) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FromMap builds triggers from the rectangle and polygon objects of the map accepted by the filter.
+// A nil filter accepts every object. Templates are applied before filtering.
+func FromMap(m *tmx.Map, filter Filter) ([]*Trigger, error) {
+	var triggers []*Trigger
+	for i := range m.ObjectGroups {
+		og := &m.ObjectGroups[i]
+		for j := range og.Objects {
+			o, err := m.ResolveTemplate(&og.Objects[j])
+			if err != nil {
+				return nil, err
+			}
+			if !isRegion(&o) {
+				continue
+			}
+			if filter != nil && !filter(og, &o) {
+				continue
+			}
+			triggers = append(triggers, &Trigger{Object: o, Group: og.Name})
+		}
+	}
+	return triggers, nil
+}
+
+func isRegion(o *tmx.Object) bool {
+	if len(o.Polygons) > 0 {
+		return true
+	}
+	return len(o.PolyLines) == 0 && o.Width > 0 && o.Height > 0
+}
@@ -0,0 +1,51 @@
+package trigger
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/talvor/tiled/tmx"
+)
+
+func loadMap(t *testing.T) *tmx.Map {
+	t.Helper()
+	m, err := tmx.LoadFile("testdata/triggers.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func names(triggers []*Trigger) []string {
+	var n []string
+	for _, t := range triggers {
+		n = append(n, t.Object.Name)
+	}
+	return n
+}
+
+func TestFromMapFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"every region", nil, []string{"inn", "cellar", "ramp", "spawn"}},
+		{"class", ByClass("Slope"), []string{"ramp"}},
+		{"group", ByGroup("spawns"), []string{"spawn"}},
+		{"property", ByProperty("music", "tavern"), []string{"inn"}},
+		{"any property value", ByProperty("music", ""), []string{"inn"}},
+		{"all", All(ByClass("Zone"), ByGroup("zones")), []string{"inn", "cellar"}},
+		{"any", Any(ByClass("Slope"), ByGroup("spawns")), []string{"ramp", "spawn"}},
+	}
+	m := loadMap(t)
+	for _, tt := range tests {
+		triggers, err := FromMap(m, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(triggers); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
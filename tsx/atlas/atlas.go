@@ -0,0 +1,317 @@
+// Package atlas packs the tiles of several tilesets into shared atlas pages, so tiles drawn together
+// come from the same texture. Tiles are padded and their borders extruded to avoid bleeding when scaled.
+package atlas
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/talvor/tiled/tsx"
+)
+
+var (
+	ErrTileTooLarge = errors.New("atlas: tile does not fit in a page")
+	ErrNoTiles      = errors.New("atlas: no tiles to pack")
+	ErrTileOutside  = errors.New("atlas: tile is outside of the tileset image")
+)
+
+const DefaultPageSize = 2048
+
+// Options control how tiles are packed.
+type Options struct {
+	// MaxWidth and MaxHeight limit the size of a page, DefaultPageSize when 0
+	MaxWidth  int
+	MaxHeight int
+	// Padding is the number of transparent pixels between tiles
+	Padding int
+	// Extrude repeats the border pixels of each tile outwards
+	Extrude int
+	// Tiles selects the tiles packed per tileset name, every tile of a tileset missing from Tiles is packed.
+	// The frames of animated tiles are always packed with them
+	Tiles map[string][]uint32
+}
+
+// Atlas maps the tiles of tilesets to regions of its pages.
+type Atlas struct {
+	Padding  int       `yaml:"padding"`
+	Extrude  int       `yaml:"extrude"`
+	Pages    []Page    `yaml:"pages"`
+	Tilesets []Tileset `yaml:"tilesets"`
+
+	regions map[string]regions
+}
+
+// regions are the packed tiles of a tileset.
+type regions struct {
+	source string
+	tiles  map[uint32]Region
+}
+
+type Page struct {
+	// Image is the file of the page, relative to the atlas file when saved
+	Image  string `yaml:"image"`
+	Width  int    `yaml:"width"`
+	Height int    `yaml:"height"`
+	// Pixels of a packed page, nil when the atlas was loaded from a file
+	Pixels *image.NRGBA `yaml:"-"`
+}
+
+// Tileset lists where the tiles of a tileset were packed.
+type Tileset struct {
+	Name string `yaml:"name"`
+	// Source is the file of the tileset, relative to the atlas file when saved
+	Source     string `yaml:"source"`
+	TileWidth  int    `yaml:"tile_width"`
+	TileHeight int    `yaml:"tile_height"`
+	Tiles      []Tile `yaml:"tiles"`
+}
+
+// Tile is the position and size of a tile in a page, excluding the extruded border. Atlases saved
+// without the size of their tiles use the tile size of the tileset.
+type Tile struct {
+	ID     uint32 `yaml:"id"`
+	Page   int    `yaml:"page"`
+	X      int    `yaml:"x"`
+	Y      int    `yaml:"y"`
+	Width  int    `yaml:"width,omitempty"`
+	Height int    `yaml:"height,omitempty"`
+}
+
+// Region is the rectangle of a tile in a page.
+type Region struct {
+	Page int
+	Rect image.Rectangle
+}
+
+// Region returns where the tile of the tileset was packed. Tiles of a tileset with the same name loaded
+// from another source are not in the atlas.
+func (a *Atlas) Region(ts *tsx.Tileset, tileID uint32) (Region, bool) {
+	if a.regions == nil {
+		a.index()
+	}
+	tr, ok := a.regions[ts.Name]
+	if !ok || (tr.source != "" && !samePath(tr.source, ts.Source)) {
+		return Region{}, false
+	}
+	r, ok := tr.tiles[tileID]
+	return r, ok
+}
+
+func (a *Atlas) index() {
+	a.regions = make(map[string]regions)
+	for _, ts := range a.Tilesets {
+		tiles := make(map[uint32]Region, len(ts.Tiles))
+		for _, t := range ts.Tiles {
+			w, h := cmp.Or(t.Width, ts.TileWidth), cmp.Or(t.Height, ts.TileHeight)
+			tiles[t.ID] = Region{
+				Page: t.Page,
+				Rect: image.Rect(t.X, t.Y, t.X+w, t.Y+h),
+			}
+		}
+		a.regions[ts.Name] = regions{source: ts.Source, tiles: tiles}
+	}
+}
+
+// samePath reports whether both paths name the same file.
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// item is a tile waiting to be packed.
+type item struct {
+	tileset int
+	id      uint32
+	src     image.Rectangle
+}
+
+// Pack packs the tiles of the tilesets into pages, loading the tileset images from their source.
+func Pack(tilesets []*tsx.Tileset, opts Options) (*Atlas, error) {
+	maxW, maxH := cmp.Or(opts.MaxWidth, DefaultPageSize), cmp.Or(opts.MaxHeight, DefaultPageSize)
+	border := opts.Extrude*2 + opts.Padding
+
+	a := &Atlas{
+		Padding: opts.Padding,
+		Extrude: opts.Extrude,
+	}
+	images := make([]*image.NRGBA, len(tilesets))
+	var items []item
+	for i, ts := range tilesets {
+		img, err := loadImage(ts.Image.Source)
+		if err != nil {
+			return nil, err
+		}
+		images[i] = img
+
+		a.Tilesets = append(a.Tilesets, Tileset{
+			Name:       ts.Name,
+			Source:     ts.Source,
+			TileWidth:  ts.TileWidth,
+			TileHeight: ts.TileHeight,
+		})
+
+		for _, id := range selectTiles(ts, opts.Tiles) {
+			src, err := ts.GetTileRect(id)
+			if err != nil {
+				return nil, fmt.Errorf("tileset: %s tile: %d %w", ts.Name, id, err)
+			}
+			if !src.In(img.Bounds()) {
+				return nil, fmt.Errorf("tileset: %s tile: %d %w", ts.Name, id, ErrTileOutside)
+			}
+			if src.Dx()+border+opts.Padding > maxW || src.Dy()+border+opts.Padding > maxH {
+				return nil, fmt.Errorf("tileset: %s tile: %d %w", ts.Name, id, ErrTileTooLarge)
+			}
+			items = append(items, item{tileset: i, id: id, src: src})
+		}
+	}
+	if len(items) == 0 {
+		return nil, ErrNoTiles
+	}
+
+	// Shelves pack best with the tallest tiles first
+	slices.SortStableFunc(items, func(a, b item) int {
+		return b.src.Dy() - a.src.Dy()
+	})
+
+	// Aim for square pages rather than filling the maximum width
+	var area, widest int
+	for _, it := range items {
+		w, h := it.src.Dx()+border, it.src.Dy()+border
+		area += w * h
+		widest = max(widest, w)
+	}
+	maxW = min(maxW, max(widest+opts.Padding, int(math.Ceil(math.Sqrt(float64(area))))+opts.Padding))
+
+	var (
+		pages  [][]item
+		places [][]image.Point
+		x, y   = opts.Padding, opts.Padding
+		shelf  int
+	)
+	newPage := func() {
+		pages = append(pages, nil)
+		places = append(places, nil)
+		x, y, shelf = opts.Padding, opts.Padding, 0
+	}
+	newPage()
+	for _, it := range items {
+		w, h := it.src.Dx()+border, it.src.Dy()+border
+		if x+w > maxW {
+			x, y, shelf = opts.Padding, y+shelf, 0
+		}
+		if y+h > maxH {
+			newPage()
+		}
+		p := len(pages) - 1
+		pages[p] = append(pages[p], it)
+		places[p] = append(places[p], image.Pt(x, y))
+		x += w
+		shelf = max(shelf, h)
+	}
+
+	for p, its := range pages {
+		var size image.Point
+		for i, it := range its {
+			size.X = max(size.X, places[p][i].X+it.src.Dx()+border)
+			size.Y = max(size.Y, places[p][i].Y+it.src.Dy()+border)
+		}
+		page := image.NewNRGBA(image.Rectangle{Max: size})
+
+		for i, it := range its {
+			at := places[p][i].Add(image.Pt(opts.Extrude, opts.Extrude))
+			blit(page, at, images[it.tileset], it.src, opts.Extrude)
+
+			ts := &a.Tilesets[it.tileset]
+			ts.Tiles = append(ts.Tiles, Tile{
+				ID:     it.id,
+				Page:   p,
+				X:      at.X,
+				Y:      at.Y,
+				Width:  it.src.Dx(),
+				Height: it.src.Dy(),
+			})
+		}
+		a.Pages = append(a.Pages, Page{Width: size.X, Height: size.Y, Pixels: page})
+	}
+
+	for i := range a.Tilesets {
+		slices.SortFunc(a.Tilesets[i].Tiles, func(a, b Tile) int { return cmp.Compare(a.ID, b.ID) })
+	}
+	return a, nil
+}
+
+// selectTiles returns the ids of the tiles of the tileset to pack, including animation frames.
+func selectTiles(ts *tsx.Tileset, selection map[string][]uint32) []uint32 {
+	ids, ok := selection[ts.Name]
+	if !ok {
+		ids = make([]uint32, ts.TileCount)
+		for i := range ids {
+			ids[i] = uint32(i)
+		}
+		return ids
+	}
+
+	seen := make(map[uint32]bool)
+	var tiles []uint32
+	add := func(id uint32) {
+		if !seen[id] && id < uint32(ts.TileCount) {
+			seen[id] = true
+			tiles = append(tiles, id)
+		}
+	}
+	for _, id := range ids {
+		add(id)
+		if ts.TileHasAnimation(id) {
+			anim, _ := ts.GetTileAnimation(id)
+			for _, f := range anim.Frames {
+				add(f.ID)
+			}
+		}
+	}
+	slices.Sort(tiles)
+	return tiles
+}
+
+// blit copies the src region of img to dst at p, repeating its border pixels extrude times outwards.
+func blit(dst *image.NRGBA, p image.Point, img *image.NRGBA, src image.Rectangle, extrude int) {
+	w, h := src.Dx(), src.Dy()
+	for y := -extrude; y < h+extrude; y++ {
+		sy := src.Min.Y + min(max(y, 0), h-1)
+		for x := -extrude; x < w+extrude; x++ {
+			sx := src.Min.X + min(max(x, 0), w-1)
+			si := img.PixOffset(sx, sy)
+			di := dst.PixOffset(p.X+x, p.Y+y)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+}
+
+func loadImage(source string) (*image.NRGBA, error) {
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tileset image %s: %w", source, err)
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tileset image %s: %w", source, err)
+	}
+
+	b := src.Bounds()
+	img := image.NewNRGBA(image.Rectangle{Max: b.Size()})
+	draw.Draw(img, img.Bounds(), src, b.Min, draw.Src)
+	return img, nil
+}
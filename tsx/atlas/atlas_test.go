@@ -0,0 +1,165 @@
+package atlas
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/talvor/tiled/tsx"
+)
+
+var (
+	red  = color.NRGBA{R: 0xff, A: 0xff}
+	blue = color.NRGBA{B: 0xff, A: 0xff}
+)
+
+// testTileset writes a tileset image of two 2x2 tiles, tile 0 is red with a blue right column and
+// tile 1 is blue, and returns the tileset drawn from it.
+func testTileset(t *testing.T) *tsx.Tileset {
+	t.Helper()
+	dir := t.TempDir()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := range 2 {
+		img.Set(0, y, red)
+		img.Set(1, y, blue)
+		img.Set(2, y, blue)
+		img.Set(3, y, blue)
+	}
+	f, err := os.Create(filepath.Join(dir, "tiles.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return &tsx.Tileset{
+		Name:       "tiles",
+		Source:     filepath.Join(dir, "tiles.tsx"),
+		TileWidth:  2,
+		TileHeight: 2,
+		TileCount:  2,
+		Columns:    2,
+		Image:      tsx.Image{Source: filepath.Join(dir, "tiles.png"), Width: 4, Height: 2},
+	}
+}
+
+func TestPackExtrudesTiles(t *testing.T) {
+	ts := testTileset(t)
+	a, err := Pack([]*tsx.Tileset{ts}, Options{Padding: 1, Extrude: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := a.Region(ts, 0)
+	if !ok {
+		t.Fatal("tile 0 is not in the atlas")
+	}
+	if r.Rect.Dx() != 2 || r.Rect.Dy() != 2 {
+		t.Fatalf("got region %v, want 2x2", r.Rect)
+	}
+
+	page := a.Pages[r.Page].Pixels
+	tests := []struct {
+		name string
+		at   image.Point
+		want color.NRGBA
+	}{
+		{"left column", r.Rect.Min, red},
+		{"right column", r.Rect.Min.Add(image.Pt(1, 0)), blue},
+		{"left border", r.Rect.Min.Add(image.Pt(-1, 0)), red},
+		{"right border", r.Rect.Min.Add(image.Pt(2, 1)), blue},
+		{"top left corner", r.Rect.Min.Add(image.Pt(-1, -1)), red},
+	}
+	for _, tt := range tests {
+		if got := page.NRGBAAt(tt.at.X, tt.at.Y); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPackTileOutsideImage(t *testing.T) {
+	ts := testTileset(t)
+	ts.TileCount, ts.Columns = 3, 3
+
+	if _, err := Pack([]*tsx.Tileset{ts}, Options{}); !errors.Is(err, ErrTileOutside) {
+		t.Errorf("got %v, want %v", err, ErrTileOutside)
+	}
+}
+
+func TestRegionChecksSource(t *testing.T) {
+	ts := testTileset(t)
+	a, err := Pack([]*tsx.Tileset{ts}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := *ts
+	other.Source = filepath.Join(t.TempDir(), "tiles.tsx")
+	if _, ok := a.Region(&other, 0); ok {
+		t.Error("got a region for a tileset of another source")
+	}
+}
+
+func TestRegionSize(t *testing.T) {
+	ts := &tsx.Tileset{Name: "tiles", TileWidth: 16, TileHeight: 16}
+	a := &Atlas{Tilesets: []Tileset{{
+		Name:       "tiles",
+		TileWidth:  16,
+		TileHeight: 16,
+		Tiles: []Tile{
+			{ID: 0, X: 1, Y: 1},
+			{ID: 1, X: 20, Y: 1, Width: 16, Height: 32},
+		},
+	}}}
+
+	tests := []struct {
+		id   uint32
+		want image.Rectangle
+	}{
+		{0, image.Rect(1, 1, 17, 17)},
+		{1, image.Rect(20, 1, 36, 33)},
+	}
+	for _, tt := range tests {
+		r, ok := a.Region(ts, tt.id)
+		if !ok || r.Rect != tt.want {
+			t.Errorf("tile %d: got %v %v, want %v", tt.id, r.Rect, ok, tt.want)
+		}
+	}
+}
+
+func TestSaveAndLoadFile(t *testing.T) {
+	ts := testTileset(t)
+	a, err := Pack([]*tsx.Tileset{ts}, Options{Padding: 2, Extrude: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "tiles.atlas")
+	if err := a.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded.Pages) != len(a.Pages) {
+		t.Fatalf("got %d pages, want %d", len(loaded.Pages), len(a.Pages))
+	}
+	if _, err := os.Stat(loaded.Pages[0].Image); err != nil {
+		t.Errorf("page image: %v", err)
+	}
+	for id := range uint32(2) {
+		want, _ := a.Region(ts, id)
+		if got, ok := loaded.Region(ts, id); !ok || got != want {
+			t.Errorf("tile %d: got %v %v, want %v", id, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package atlas
+
+import (
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var ErrPageNotPacked = errors.New("atlas: page has no pixels")
+
+// Save writes the atlas metadata to fileName, eg. sprites.atlas, and each page to a PNG named after it,
+// eg. sprites_0.png. The page image and tileset paths are written relative to the metadata file.
+func (a *Atlas) Save(fileName string) error {
+	dir := filepath.Dir(fileName)
+	base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+
+	for i := range a.Pages {
+		page := &a.Pages[i]
+		if page.Pixels == nil {
+			return fmt.Errorf("page: %d %w", i, ErrPageNotPacked)
+		}
+
+		page.Image = fmt.Sprintf("%s_%d.png", base, i)
+		if err := savePNG(filepath.Join(dir, page.Image), page); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("file: %s %w", fileName, err)
+	}
+	defer f.Close()
+
+	saved := *a
+	saved.Tilesets = slices.Clone(a.Tilesets)
+	for i := range saved.Tilesets {
+		saved.Tilesets[i].Source = relativePath(dir, saved.Tilesets[i].Source)
+	}
+
+	e := yaml.NewEncoder(f)
+	e.SetIndent(2)
+	if err := e.Encode(&saved); err != nil {
+		return fmt.Errorf("file: %s %w", fileName, err)
+	}
+	return e.Close()
+}
+
+// MarshalYAML writes a tile on a single line, atlases list thousands of them.
+func (t Tile) MarshalYAML() (interface{}, error) {
+	type plain Tile
+	var node yaml.Node
+	if err := node.Encode(plain(t)); err != nil {
+		return nil, err
+	}
+	node.Style = yaml.FlowStyle
+	return &node, nil
+}
+
+// relativePath returns the path of fileName relative to dir, or fileName when there is none.
+func relativePath(dir string, fileName string) string {
+	if fileName == "" {
+		return fileName
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fileName
+	}
+	absFile, err := filepath.Abs(fileName)
+	if err != nil {
+		return fileName
+	}
+	rel, err := filepath.Rel(absDir, absFile)
+	if err != nil {
+		return fileName
+	}
+	return filepath.ToSlash(rel)
+}
+
+func savePNG(fileName string, page *Page) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("file: %s %w", fileName, err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, page.Pixels); err != nil {
+		return fmt.Errorf("file: %s %w", fileName, err)
+	}
+	return nil
+}
+
+// LoadFile loads atlas metadata written by Save. The page images and tilesets are resolved relative to
+// the file, the page images are left for the renderer to load.
+func LoadFile(fileName string) (*Atlas, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a := &Atlas{}
+	if err := yaml.NewDecoder(f).Decode(a); err != nil {
+		return nil, fmt.Errorf("file: %s %w", fileName, err)
+	}
+
+	dir := filepath.Dir(fileName)
+	for i := range a.Pages {
+		a.Pages[i].Image = filepath.Join(dir, a.Pages[i].Image)
+	}
+	for i := range a.Tilesets {
+		if src := a.Tilesets[i].Source; src != "" && !filepath.IsAbs(src) {
+			a.Tilesets[i].Source = filepath.Join(dir, src)
+		}
+	}
+	return a, nil
+}
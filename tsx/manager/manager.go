@@ -6,8 +6,9 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/internal/pathutil"
 	"github.com/talvor/tiled/tsx"
+	"github.com/talvor/tiled/tsx/atlas"
 )
 
 var (
@@ -20,6 +21,8 @@ type TilesetManager struct {
 	TilesetsByName   map[string]*tsx.Tileset
 	TilesetsBySource map[string]*tsx.Tileset
 	TilesetGroups    map[string]tsx.TilesetGroup
+	// Atlases found in the base directories, the renderer draws their tiles from the atlas pages
+	Atlases []*atlas.Atlas
 }
 
 func (tm *TilesetManager) GetTilesets(names []string) []*tsx.Tileset {
@@ -76,6 +79,12 @@ func (tm *TilesetManager) AddTileset(sourcePath string) (*tsx.Tileset, error) {
 	return ts, nil
 }
 
+// RegisterTileset adds a tileset that was not loaded from a TSX file, eg. one generated for a sprite sheet.
+func (tm *TilesetManager) RegisterTileset(ts *tsx.Tileset) {
+	tm.TilesetsByName[ts.Name] = ts
+	tm.TilesetsBySource[ts.Source] = ts
+}
+
 func (tm *TilesetManager) AddTilesetGroupBySource(name string, sources []string) error {
 	var tilesets tsx.TilesetGroup
 	for _, source := range sources {
@@ -135,7 +144,7 @@ func NewManager(baseDirs []string) *TilesetManager {
 
 	// Load tilesets from the base directories
 	for _, baseDir := range baseDirs {
-		if err := common.PathShouldBeDirectory(baseDir); err != nil {
+		if err := pathutil.PathShouldBeDirectory(baseDir); err != nil {
 			fmt.Printf("Error: %s %v\n", baseDir, err)
 			continue
 		}
@@ -149,7 +158,7 @@ func NewManager(baseDirs []string) *TilesetManager {
 }
 
 func loadTilesets(tm *TilesetManager, baseDir string) error {
-	tsxFiles, err := findTSXFiles(baseDir)
+	tsxFiles, err := findFiles(baseDir, ".tsx")
 	if err != nil {
 		return fmt.Errorf("error loading tilesets: %s %w", baseDir, err)
 	}
@@ -164,18 +173,30 @@ func loadTilesets(tm *TilesetManager, baseDir string) error {
 		tm.TilesetsBySource[ts.Source] = ts
 	}
 
+	atlasFiles, err := findFiles(baseDir, ".atlas")
+	if err != nil {
+		return fmt.Errorf("error loading atlases: %s %w", baseDir, err)
+	}
+	for _, atlasFile := range atlasFiles {
+		a, err := atlas.LoadFile(atlasFile)
+		if err != nil {
+			return fmt.Errorf("error loading atlases: %s %w", baseDir, err)
+		}
+		tm.Atlases = append(tm.Atlases, a)
+	}
+
 	return nil
 }
 
-func findTSXFiles(dir string) ([]string, error) {
-	var tsxFiles []string
+func findFiles(dir string, ext string) ([]string, error) {
+	var files []string
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() && filepath.Ext(path) == ".tsx" {
-			tsxFiles = append(tsxFiles, path)
+		if !info.IsDir() && filepath.Ext(path) == ext {
+			files = append(files, path)
 		}
 		return nil
 	})
@@ -183,5 +204,5 @@ func findTSXFiles(dir string) ([]string, error) {
 		return nil, err
 	}
 
-	return tsxFiles, nil
+	return files, nil
 }
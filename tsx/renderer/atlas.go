@@ -0,0 +1,100 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/pkg/errors"
+	"github.com/talvor/tiled/tsx"
+	"github.com/talvor/tiled/tsx/atlas"
+)
+
+// atlasPages holds the images of the pages of an atlas, loaded on first use.
+type atlasPages struct {
+	atlas  *atlas.Atlas
+	images []*ebiten.Image
+}
+
+// UseAtlas draws the tiles packed in the atlas from its pages instead of their tileset images, so tiles
+// of different tilesets can be batched together. Atlases added later take precedence, tiles missing
+// from every atlas are drawn from their tileset image. Recolored tiles always use the tileset image.
+func (er *Renderer) UseAtlas(a *atlas.Atlas) {
+	er.Flush()
+	er.atlases = append([]*atlasPages{{atlas: a, images: make([]*ebiten.Image, len(a.Pages))}}, er.atlases...)
+}
+
+// LoadAtlas loads an atlas file written by atlas.Atlas.Save and uses it.
+func (er *Renderer) LoadAtlas(fileName string) error {
+	a, err := atlas.LoadFile(fileName)
+	if err != nil {
+		return errors.Wrap(err, "failed to load atlas")
+	}
+	er.UseAtlas(a)
+	return nil
+}
+
+// tileImage returns the image holding the tile and the rectangle of the tile in it.
+func (er *Renderer) tileImage(ts *tsx.Tileset, tileId uint32, palette *Palette) (*ebiten.Image, image.Rectangle, error) {
+	if palette == nil {
+		for _, ap := range er.atlases {
+			if img, rect, ok, err := ap.tile(ts, tileId); ok || err != nil {
+				return img, rect, err
+			}
+		}
+
+		if er.Extrude > 0 {
+			ap, err := er.extrudedTileset(ts)
+			if err != nil {
+				return nil, image.Rectangle{}, err
+			}
+			if img, rect, ok, err := ap.tile(ts, tileId); ok || err != nil {
+				return img, rect, err
+			}
+		}
+	}
+
+	img, err := er.tilesetImage(ts, palette)
+	if err != nil {
+		return nil, image.Rectangle{}, err
+	}
+	rect, err := ts.GetTileRect(tileId)
+	if err != nil {
+		return nil, image.Rectangle{}, fmt.Errorf("failed to get tile rect for tile %d in tileset %s: %w", tileId, ts.Name, err)
+	}
+	return img, rect, nil
+}
+
+// tile returns the page and rectangle of a tile, ok is false when the tile is not in the atlas.
+func (ap *atlasPages) tile(ts *tsx.Tileset, tileId uint32) (*ebiten.Image, image.Rectangle, bool, error) {
+	region, ok := ap.atlas.Region(ts, tileId)
+	if !ok {
+		return nil, image.Rectangle{}, false, nil
+	}
+	img, err := ap.page(region.Page)
+	if err != nil {
+		return nil, image.Rectangle{}, false, err
+	}
+	return img, region.Rect, true, nil
+}
+
+func (ap *atlasPages) page(idx int) (*ebiten.Image, error) {
+	if img := ap.images[idx]; img != nil {
+		return img, nil
+	}
+
+	var img *ebiten.Image
+	page := &ap.atlas.Pages[idx]
+	if page.Pixels != nil {
+		img = ebiten.NewImageFromImage(page.Pixels)
+	} else {
+		var err error
+		if img, _, err = ebitenutil.NewImageFromFile(page.Image); err != nil {
+			return nil, errors.Wrap(err, "failed to load atlas page")
+		}
+	}
+
+	ap.images[idx] = img
+	return img, nil
+}
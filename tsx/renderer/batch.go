@@ -0,0 +1,116 @@
+package renderer
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// maxBatchQuads keeps the vertices of a batch addressable by uint16 indices, each quad uses 4.
+const maxBatchQuads = (1 << 16) / 4
+
+// batch accumulates the tiles drawn from one image to one screen and submits them with a single
+// DrawTriangles call. It is flushed when the image, screen or filter changes.
+type batch struct {
+	screen   *ebiten.Image
+	image    *ebiten.Image
+	filter   ebiten.Filter
+	vertices []ebiten.Vertex
+	indices  []uint16
+	// drawCalls counts the DrawTriangles calls since the batch began
+	drawCalls int
+}
+
+// BeginBatch starts batching: tiles drawn by the DrawTile methods are queued and drawn together
+// when the batch is flushed. Tiles drawn from the same tileset image are drawn with a single call.
+// EndBatch must be called before the screen is presented.
+func (er *Renderer) BeginBatch() {
+	if er.batch == nil {
+		er.batch = &batch{}
+	}
+}
+
+// EndBatch draws the queued tiles and stops batching. It returns the number of draw calls used.
+func (er *Renderer) EndBatch() int {
+	if er.batch == nil {
+		return 0
+	}
+	er.batch.flush()
+	calls := er.batch.drawCalls
+	er.batch = nil
+	return calls
+}
+
+// Batching reports whether BeginBatch has been called without EndBatch.
+func (er *Renderer) Batching() bool {
+	return er.batch != nil
+}
+
+// Flush draws the queued tiles, eg. before drawing to the screen without the renderer.
+func (er *Renderer) Flush() {
+	if er.batch != nil {
+		er.batch.flush()
+	}
+}
+
+// drawImage draws the region src of img to the screen, queued when batching.
+func (er *Renderer) drawImage(screen *ebiten.Image, img *ebiten.Image, src image.Rectangle, op *ebiten.DrawImageOptions) {
+	if er.SnapPixels {
+		// Snap a copy, the options may be the caller's when the tile is not flipped
+		snapped := *op
+		SnapGeoM(&snapped.GeoM, float64(src.Dx()), float64(src.Dy()))
+		op = &snapped
+	}
+
+	// Custom blending is not batched, draw it in order with the queued tiles
+	if er.batch == nil || op.Blend != (ebiten.Blend{}) {
+		er.Flush()
+		screen.DrawImage(img.SubImage(src).(*ebiten.Image), op)
+		return
+	}
+	er.batch.add(screen, img, src, op)
+}
+
+func (b *batch) add(screen *ebiten.Image, img *ebiten.Image, src image.Rectangle, op *ebiten.DrawImageOptions) {
+	if b.screen != screen || b.image != img || b.filter != op.Filter || len(b.indices)/6 >= maxBatchQuads {
+		b.flush()
+		b.screen = screen
+		b.image = img
+		b.filter = op.Filter
+	}
+
+	// Vertex colors use the premultiplied color scale of the draw options
+	cr, cg, cb, ca := op.ColorScale.R(), op.ColorScale.G(), op.ColorScale.B(), op.ColorScale.A()
+	w, h := float64(src.Dx()), float64(src.Dy())
+
+	base := uint16(len(b.vertices))
+	for _, corner := range [4][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+		dx, dy := op.GeoM.Apply(corner[0], corner[1])
+		b.vertices = append(b.vertices, ebiten.Vertex{
+			DstX:   float32(dx),
+			DstY:   float32(dy),
+			SrcX:   float32(src.Min.X) + float32(corner[0]),
+			SrcY:   float32(src.Min.Y) + float32(corner[1]),
+			ColorR: cr,
+			ColorG: cg,
+			ColorB: cb,
+			ColorA: ca,
+		})
+	}
+	b.indices = append(b.indices, base, base+1, base+2, base+1, base+3, base+2)
+}
+
+func (b *batch) flush() {
+	if len(b.indices) == 0 {
+		return
+	}
+
+	b.screen.DrawTriangles(b.vertices, b.indices, b.image, &ebiten.DrawTrianglesOptions{
+		ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha,
+		Filter:         b.filter,
+	})
+	b.drawCalls++
+
+	b.vertices = b.vertices[:0]
+	b.indices = b.indices[:0]
+}
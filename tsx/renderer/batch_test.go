@@ -0,0 +1,49 @@
+package renderer
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestBatchIndicesFitUint16(t *testing.T) {
+	screen, img := ebiten.NewImage(16, 16), ebiten.NewImage(16, 16)
+	b := &batch{}
+	for range maxBatchQuads {
+		b.add(screen, img, image.Rect(0, 0, 16, 16), &ebiten.DrawImageOptions{})
+	}
+
+	if b.drawCalls != 0 {
+		t.Fatalf("a full batch was flushed after %d calls", b.drawCalls)
+	}
+	if len(b.vertices) != math.MaxUint16+1 {
+		t.Errorf("got %d vertices, want %d", len(b.vertices), math.MaxUint16+1)
+	}
+	if got := b.indices[len(b.indices)-1]; got != math.MaxUint16 {
+		t.Errorf("last index %d, want %d", got, math.MaxUint16)
+	}
+}
+
+func TestSnapPixelsKeepsCallerOptions(t *testing.T) {
+	screen, img := ebiten.NewImage(32, 16), ebiten.NewImage(16, 16)
+	er := &Renderer{SnapPixels: true, batch: &batch{}}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(10.4, 0.6)
+	want := op.GeoM
+	for range 2 {
+		er.drawImage(screen, img, image.Rect(0, 0, 16, 16), op)
+		if op.GeoM != want {
+			t.Fatalf("the options of the caller changed to %v", op.GeoM.String())
+		}
+	}
+
+	// Both draws are snapped from the same position
+	for i := range 2 {
+		if v := er.batch.vertices[i*4]; v.DstX != 10 || v.DstY != 1 {
+			t.Errorf("draw %d: at %v,%v, want 10,1", i, v.DstX, v.DstY)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/pkg/errors"
+	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/render"
+	"github.com/talvor/tiled/tsx"
+)
+
+// Canvas draws through the renderer to an ebiten image, so the drawing of the render package can
+// share the batches and image cache of the renderer. Tiles are drawn like DrawTile, from atlases
+// and extruded tilesets and with the tile animation overrides.
+type Canvas struct {
+	Screen   *ebiten.Image
+	Renderer *Renderer
+	// Filter and Blend are used for every image drawn on the canvas
+	Filter ebiten.Filter
+	Blend  ebiten.Blend
+}
+
+// NewCanvas returns a canvas drawing to screen.
+func (er *Renderer) NewCanvas(screen *ebiten.Image) *Canvas {
+	return &Canvas{
+		Screen:   screen,
+		Renderer: er,
+	}
+}
+
+// LoadImage loads an image file, tileset images already loaded by the renderer are reused.
+func (c *Canvas) LoadImage(source string) (render.Image, error) {
+	if img, ok := c.Renderer.canvasImages[source]; ok {
+		return img, nil
+	}
+
+	var img *ebiten.Image
+	var err error
+	if ts := c.Renderer.tilesetWithImage(source); ts != nil {
+		img, err = c.Renderer.loadTilesetImage(ts)
+	} else if img, _, err = ebitenutil.NewImageFromFile(source); err != nil {
+		err = errors.Wrap(err, "failed to load image")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.Renderer.canvasImages[source] = img
+	return img, nil
+}
+
+// DrawImage draws the region src of an image loaded by the canvas.
+func (c *Canvas) DrawImage(img render.Image, src image.Rectangle, op *render.Options) error {
+	eimg, ok := img.(*ebiten.Image)
+	if !ok {
+		return fmt.Errorf("image: %T %w", img, render.ErrUnsupportedImage)
+	}
+
+	eop := c.drawImageOptions(op.Transform(src.Dx(), src.Dy()), op.ColorScale)
+	c.Renderer.drawImage(c.Screen, eimg, src, eop)
+	return nil
+}
+
+// DrawTile draws a tile of the tileset like Renderer.DrawTile.
+func (c *Canvas) DrawTile(ts *tsx.Tileset, tileID uint32, op *render.Options) error {
+	return c.Renderer.DrawTile(ts, tileID, &common.DrawOptions{
+		Screen:         c.Screen,
+		Op:             c.drawImageOptions(op.GeoM, op.ColorScale),
+		FlipHorizontal: op.FlipHorizontal,
+		FlipVertical:   op.FlipVertical,
+		FlipDiagonal:   op.FlipDiagonal,
+	})
+}
+
+// RenderOptions returns the transform and color scale of op as options of the render package, eg. to draw
+// with a camera set up for ebiten.
+func RenderOptions(op *ebiten.DrawImageOptions) *render.Options {
+	ro := &render.Options{}
+	for i := range 2 {
+		for j := range 3 {
+			ro.GeoM.SetElement(i, j, op.GeoM.Element(i, j))
+		}
+	}
+	ro.ColorScale.Scale(op.ColorScale.R(), op.ColorScale.G(), op.ColorScale.B(), op.ColorScale.A())
+	return ro
+}
+
+func (c *Canvas) drawImageOptions(g render.GeoM, cs render.ColorScale) *ebiten.DrawImageOptions {
+	eop := &ebiten.DrawImageOptions{Filter: c.Filter, Blend: c.Blend}
+	for i := range 2 {
+		for j := range 3 {
+			eop.GeoM.SetElement(i, j, g.Element(i, j))
+		}
+	}
+	eop.ColorScale.Scale(cs.R(), cs.G(), cs.B(), cs.A())
+	return eop
+}
+
+// tilesetWithImage returns the tileset drawn from the image file, nil when there is none.
+func (er *Renderer) tilesetWithImage(source string) *tsx.Tileset {
+	for _, ts := range er.TilesetManager.TilesetsByName {
+		if ts.Image.Source == source {
+			return ts
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package renderer
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/tmx"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var (
+	ErrPaletteNotFound = errors.New("palette not found")
+	ErrInvalidPalette  = errors.New("invalid palette")
+)
+
+// LoadPaletteFile loads the palettes of a YAML palette file
+//
+//	palettes:
+//	  - name: red_team
+//	    colors:
+//	      "#3c8d2f": "#b02e26"
+//
+// Colors are written as #RRGGBB or #AARRGGBB, see tmx.ParseColor.
+func LoadPaletteFile(fileName string) ([]*Palette, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var file struct {
+		Palettes []struct {
+			Name   string            `yaml:"name"`
+			Colors map[string]string `yaml:"colors"`
+		} `yaml:"palettes"`
+	}
+	if err := yaml.NewDecoder(f).Decode(&file); err != nil {
+		return nil, fmt.Errorf("file: %s %w", fileName, err)
+	}
+
+	palettes := make([]*Palette, 0, len(file.Palettes))
+	for _, p := range file.Palettes {
+		palette := NewPalette(p.Name)
+		for source, target := range p.Colors {
+			s, err := parseColor(source)
+			if err != nil {
+				return nil, fmt.Errorf("file: %s palette: %s %w", fileName, p.Name, err)
+			}
+			t, err := parseColor(target)
+			if err != nil {
+				return nil, fmt.Errorf("file: %s palette: %s %w", fileName, p.Name, err)
+			}
+			palette.Colors[s] = t
+		}
+		palettes = append(palettes, palette)
+	}
+	return palettes, nil
+}
+
+// LoadPaletteImage loads the palettes of a palette image. The first row of pixels holds the source
+// colors and every other row the target colors of a palette. A palette image with two rows has a
+// single palette named after the file, eg. "red_team" for red_team.png. Otherwise the palettes
+// are numbered from 1, eg. "enemies_1", "enemies_2".
+func LoadPaletteImage(fileName string) ([]*Palette, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("file: %s %w", fileName, err)
+	}
+
+	b := img.Bounds()
+	if b.Dy() < 2 {
+		return nil, fmt.Errorf("file: %s %w", fileName, ErrInvalidPalette)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	palettes := make([]*Palette, 0, b.Dy()-1)
+	for y := b.Min.Y + 1; y < b.Max.Y; y++ {
+		palette := NewPalette(name)
+		if b.Dy() > 2 {
+			palette.Name = fmt.Sprintf("%s_%d", name, y-b.Min.Y)
+		}
+		for x := b.Min.X; x < b.Max.X; x++ {
+			palette.Set(img.At(x, b.Min.Y), img.At(x, y))
+		}
+		palettes = append(palettes, palette)
+	}
+	return palettes, nil
+}
+
+// parseColor parses a palette color, written like the colors of Tiled maps. Unlike map colors a
+// palette color can not be empty.
+func parseColor(s string) (color.NRGBA, error) {
+	if strings.TrimPrefix(s, "#") == "" {
+		return color.NRGBA{}, fmt.Errorf("color: %q %w", s, tmx.ErrInvalidColor)
+	}
+	return tmx.ParseColor(s)
+}
+
+// LoadPalettes loads a palette file, or a palette image for .png files, and registers its palettes.
+func (er *Renderer) LoadPalettes(fileName string) error {
+	var palettes []*Palette
+	var err error
+	if strings.EqualFold(filepath.Ext(fileName), ".png") {
+		palettes, err = LoadPaletteImage(fileName)
+	} else {
+		palettes, err = LoadPaletteFile(fileName)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, p := range palettes {
+		er.RegisterPalette(p)
+	}
+	return nil
+}
+
+// RegisterPalette makes the palette available by name, replacing a palette with the same name.
+func (er *Renderer) RegisterPalette(p *Palette) {
+	if old, ok := er.Palettes[p.Name]; ok {
+		er.ReleasePalette(old)
+	}
+	er.Palettes[p.Name] = p
+}
+
+func (er *Renderer) GetPalette(name string) (*Palette, error) {
+	p, ok := er.Palettes[name]
+	if !ok {
+		return nil, fmt.Errorf("palette: %s %w", name, ErrPaletteNotFound)
+	}
+	return p, nil
+}
+
+// ReleasePalette deallocates the recolored tileset images baked for the palette. Palettes built at
+// runtime are cached until they are released, a released palette is baked again when drawn.
+func (er *Renderer) ReleasePalette(p *Palette) {
+	er.Flush()
+	for key, img := range er.paletteImages {
+		if key.palette == p {
+			img.Deallocate()
+			delete(er.paletteImages, key)
+		}
+	}
+}
+
+// DrawTileWithPaletteName draws a tile of the named tileset recolored with a registered palette.
+func (er *Renderer) DrawTileWithPaletteName(tilesetName string, tileId uint32, paletteName string, opts *common.DrawOptions) error {
+	ts := er.TilesetManager.GetTilesetByName(tilesetName)
+	if ts == nil {
+		return fmt.Errorf("failed to find tileset with name %s: %w", tilesetName, ErrTileset)
+	}
+	p, err := er.GetPalette(paletteName)
+	if err != nil {
+		return err
+	}
+
+	return er.DrawTileWithPalette(ts, tileId, p, opts)
+}
+
+// DrawTilesetWithPalette draws the whole image of the named tileset recolored with the palette.
+func (er *Renderer) DrawTilesetWithPalette(name string, palette *Palette, screen *ebiten.Image, op *ebiten.DrawImageOptions) error {
+	ts := er.TilesetManager.GetTilesetByName(name)
+	if ts == nil {
+		return fmt.Errorf("failed to find tileset with name %s: %w", name, ErrTileset)
+	}
+
+	img, err := er.tilesetImage(ts, palette)
+	if err != nil {
+		return err
+	}
+
+	er.Flush()
+	screen.DrawImage(img, op)
+
+	return nil
+}
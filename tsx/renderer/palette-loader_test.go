@@ -0,0 +1,74 @@
+package renderer
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/talvor/tiled/tmx"
+)
+
+func writePalette(t *testing.T, source string) string {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), "palettes.yaml")
+	if err := os.WriteFile(fileName, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestLoadPaletteFile(t *testing.T) {
+	palettes, err := LoadPaletteFile(writePalette(t, `palettes:
+  - name: red_team
+    colors:
+      "#3c8d2f": "#80b02e26"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := palettes[0].Colors[color.NRGBA{R: 0x3c, G: 0x8d, B: 0x2f, A: 0xff}]
+	if got != (color.NRGBA{R: 0xb0, G: 0x2e, B: 0x26, A: 0x80}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestLoadPaletteFileRejectsEmptyColor(t *testing.T) {
+	_, err := LoadPaletteFile(writePalette(t, `palettes:
+  - name: red_team
+    colors:
+      "#3c8d2f": ""
+`))
+	if !errors.Is(err, tmx.ErrInvalidColor) {
+		t.Errorf("got %v, want an invalid color", err)
+	}
+}
+
+func TestReleasePalette(t *testing.T) {
+	day, night := NewPalette("day"), NewPalette("night")
+	er := &Renderer{
+		Palettes: make(map[string]*Palette),
+		paletteImages: map[paletteKey]*ebiten.Image{
+			{"grass", day}:   ebiten.NewImage(1, 1),
+			{"water", day}:   ebiten.NewImage(1, 1),
+			{"grass", night}: ebiten.NewImage(1, 1),
+		},
+	}
+
+	er.ReleasePalette(day)
+	if len(er.paletteImages) != 1 {
+		t.Errorf("got %d images, want only the image of the other palette", len(er.paletteImages))
+	}
+	if _, ok := er.paletteImages[paletteKey{"grass", night}]; !ok {
+		t.Error("the image of the other palette was released")
+	}
+
+	// Replacing a registered palette releases the old one
+	er.RegisterPalette(night)
+	er.RegisterPalette(NewPalette("night"))
+	if len(er.paletteImages) != 0 {
+		t.Errorf("got %d images after replacing the palette, want 0", len(er.paletteImages))
+	}
+}
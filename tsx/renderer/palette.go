@@ -0,0 +1,106 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/png"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/pkg/errors"
+	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/tsx"
+)
+
+// Palette maps colors of a tileset image to the colors they are drawn with, eg. for team colors.
+// Recolored tileset images are baked once per palette and kept until the palette is released with
+// Renderer.ReleasePalette, changing the colors of a palette that has already been drawn has no effect
+// before it is released.
+type Palette struct {
+	Name   string
+	Colors map[color.NRGBA]color.NRGBA
+}
+
+func NewPalette(name string) *Palette {
+	return &Palette{
+		Name:   name,
+		Colors: make(map[color.NRGBA]color.NRGBA),
+	}
+}
+
+// Set maps the source color to the target color.
+func (p *Palette) Set(source color.Color, target color.Color) {
+	p.Colors[toNRGBA(source)] = toNRGBA(target)
+}
+
+// Recolor returns a copy of img with the colors of the palette replaced.
+func (p *Palette) Recolor(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	result := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(result, result.Bounds(), img, b.Min, draw.Src)
+
+	for i := 0; i < len(result.Pix); i += 4 {
+		c := color.NRGBA{R: result.Pix[i], G: result.Pix[i+1], B: result.Pix[i+2], A: result.Pix[i+3]}
+		if target, ok := p.Colors[c]; ok {
+			result.Pix[i] = target.R
+			result.Pix[i+1] = target.G
+			result.Pix[i+2] = target.B
+			result.Pix[i+3] = target.A
+		}
+	}
+	return result
+}
+
+func toNRGBA(c color.Color) color.NRGBA {
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
+type paletteKey struct {
+	tileset string
+	palette *Palette
+}
+
+// DrawTileWithPalette draws a tile of the tileset recolored with the palette. A nil palette draws
+// the tile unchanged.
+func (er *Renderer) DrawTileWithPalette(ts *tsx.Tileset, tileId uint32, palette *Palette, opts *common.DrawOptions) error {
+	return er.drawTileWithPalette(ts, er.animatedTileID(ts, tileId), palette, opts)
+}
+
+func (er *Renderer) drawTileWithPalette(ts *tsx.Tileset, tileId uint32, palette *Palette, opts *common.DrawOptions) error {
+	img, rect, err := er.tileImage(ts, tileId, palette)
+	if err != nil {
+		return err
+	}
+
+	er.drawImage(opts.Screen, img, rect, flipOptions(rect.Dx(), rect.Dy(), opts))
+
+	return nil
+}
+
+// tilesetImage returns the image of the tileset, recolored when palette is not nil.
+func (er *Renderer) tilesetImage(ts *tsx.Tileset, palette *Palette) (*ebiten.Image, error) {
+	if palette == nil {
+		return er.loadTilesetImage(ts)
+	}
+
+	key := paletteKey{ts.Name, palette}
+	if img, ok := er.paletteImages[key]; ok {
+		return img, nil
+	}
+
+	f, err := os.Open(ts.Image.Source)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load tileset image")
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load tileset image")
+	}
+
+	img := ebiten.NewImageFromImage(palette.Recolor(src))
+	er.paletteImages[key] = img
+	return img, nil
+}
@@ -0,0 +1,177 @@
+package renderer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/talvor/tiled/common"
+)
+
+var ErrUnknownSlot = errors.New("unknown paper doll slot")
+
+const (
+	SlotBody  = "body"
+	SlotTorso = "torso"
+	SlotLegs  = "legs"
+	SlotHead  = "head"
+	SlotHands = "hands"
+)
+
+// DefaultSlots are the slots of a paper doll created without slots, back to front.
+var DefaultSlots = []string{SlotBody, SlotLegs, SlotTorso, SlotHead, SlotHands}
+
+// PaperDollSlot is a layer of a paper doll drawn from a tileset with the same layout as the other slots.
+type PaperDollSlot struct {
+	Name    string
+	Tileset string
+	// Palette recolors the tileset of the slot, optional
+	Palette *Palette
+}
+
+// A paper doll is a compound sprite made of named slots, eg. the body and the equipment of a
+// character. The tileset of every slot can be swapped and the draw order can change with the facing.
+type PaperDoll struct {
+	Renderer *Renderer
+	// Facing selects the draw order set with SetOrder
+	Facing string
+
+	slots  map[string]*PaperDollSlot
+	order  []string
+	orders map[string][]string
+}
+
+// NewPaperDoll returns a paper doll with the slots drawn back to front, DefaultSlots when none are given.
+func NewPaperDoll(renderer *Renderer, slots ...string) *PaperDoll {
+	if len(slots) == 0 {
+		slots = DefaultSlots
+	}
+
+	pd := &PaperDoll{
+		Renderer: renderer,
+		slots:    make(map[string]*PaperDollSlot, len(slots)),
+		order:    slices.Clone(slots),
+		orders:   make(map[string][]string),
+	}
+	for _, name := range slots {
+		pd.slots[name] = &PaperDollSlot{Name: name}
+	}
+	return pd
+}
+
+func (pd *PaperDoll) slot(name string) (*PaperDollSlot, error) {
+	s, ok := pd.slots[name]
+	if !ok {
+		return nil, fmt.Errorf("slot: %s %w", name, ErrUnknownSlot)
+	}
+	return s, nil
+}
+
+// Equip draws the slot from the tileset.
+func (pd *PaperDoll) Equip(slot string, tileset string) error {
+	s, err := pd.slot(slot)
+	if err != nil {
+		return err
+	}
+	s.Tileset = tileset
+	return nil
+}
+
+// Unequip leaves the slot empty, it is not drawn.
+func (pd *PaperDoll) Unequip(slot string) error {
+	return pd.Equip(slot, "")
+}
+
+// Equipped returns the tileset of the slot, empty when nothing is equipped.
+func (pd *PaperDoll) Equipped(slot string) string {
+	if s, ok := pd.slots[slot]; ok {
+		return s.Tileset
+	}
+	return ""
+}
+
+// SetPalette recolors the slot with the palette, nil removes the palette.
+func (pd *PaperDoll) SetPalette(slot string, palette *Palette) error {
+	s, err := pd.slot(slot)
+	if err != nil {
+		return err
+	}
+	s.Palette = palette
+	return nil
+}
+
+// SetOrder sets the draw order, back to front, used for a facing, eg. the hands behind the body
+// when facing up. Slots that are not listed are drawn after them in their default order.
+func (pd *PaperDoll) SetOrder(facing string, slots ...string) error {
+	for _, name := range slots {
+		if _, err := pd.slot(name); err != nil {
+			return err
+		}
+	}
+	pd.orders[facing] = slices.Clone(slots)
+	return nil
+}
+
+func (pd *PaperDoll) SetFacing(facing string) {
+	pd.Facing = facing
+}
+
+// HasSlot reports whether the paper doll has a slot with the name.
+func (pd *PaperDoll) HasSlot(name string) bool {
+	_, ok := pd.slots[name]
+	return ok
+}
+
+// Slot returns the slot with the name, or nil.
+func (pd *PaperDoll) Slot(name string) *PaperDollSlot {
+	return pd.slots[name]
+}
+
+// Layers returns the equipped slots in draw order for the facing.
+func (pd *PaperDoll) Layers(facing string) []PaperDollSlot {
+	order := pd.order
+	if o, ok := pd.orders[facing]; ok {
+		order = o
+		for _, name := range pd.order {
+			if !slices.Contains(order, name) {
+				order = append(slices.Clip(order), name)
+			}
+		}
+	}
+
+	layers := make([]PaperDollSlot, 0, len(order))
+	for _, name := range order {
+		if s := pd.slots[name]; s.Tileset != "" {
+			layers = append(layers, *s)
+		}
+	}
+	return layers
+}
+
+// CompoundSprite returns a compound sprite drawing the equipped slots for the facing.
+func (pd *PaperDoll) CompoundSprite(facing string) *CompoundSprite {
+	layers := pd.Layers(facing)
+
+	cs := &CompoundSprite{
+		Tilesets: make([]string, 0, len(layers)),
+		Palettes: make([]*Palette, 0, len(layers)),
+		Renderer: pd.Renderer,
+	}
+	for _, l := range layers {
+		cs.Tilesets = append(cs.Tilesets, l.Tileset)
+		cs.Palettes = append(cs.Palettes, l.Palette)
+	}
+	return cs
+}
+
+func (pd *PaperDoll) Draw(id interface{}, opts *common.DrawOptions) error {
+	return pd.CompoundSprite(pd.Facing).Draw(id, opts)
+}
+
+func (pd *PaperDoll) renderer() *Renderer {
+	return pd.Renderer
+}
+
+func (pd *PaperDoll) DrawWithAnimation(name string, duration int, opts *common.DrawOptions) error {
+	return pd.CompoundSprite(pd.Facing).DrawWithAnimation(name, duration, opts)
+}
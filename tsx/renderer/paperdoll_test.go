@@ -0,0 +1,25 @@
+package renderer
+
+import "testing"
+
+func TestCompoundSpritePalettesPerSlot(t *testing.T) {
+	pd := NewPaperDoll(nil, SlotBody, SlotTorso)
+	red, blue := NewPalette("red"), NewPalette("blue")
+	pd.Equip(SlotBody, "cloth")
+	pd.Equip(SlotTorso, "cloth")
+	pd.SetPalette(SlotBody, red)
+	pd.SetPalette(SlotTorso, blue)
+
+	cs := pd.CompoundSprite("")
+	if len(cs.Tilesets) != 2 {
+		t.Fatalf("got %d parts, want 2", len(cs.Tilesets))
+	}
+	if cs.palette(0) != red || cs.palette(1) != blue {
+		t.Error("slots sharing a tileset share a palette")
+	}
+
+	pd.SetPalette(SlotBody, nil)
+	if cs := pd.CompoundSprite(""); cs.palette(0) != nil || cs.palette(1) != blue {
+		t.Error("removing the body palette changed the torso")
+	}
+}
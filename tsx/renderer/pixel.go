@@ -0,0 +1,211 @@
+package renderer
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/talvor/tiled/tsx"
+	"github.com/talvor/tiled/tsx/atlas"
+)
+
+// SetSnapPixels rounds the corners of every tile drawn by the renderer to whole screen pixels. Tiles drawn
+// at fractional camera positions or zoom levels then meet without seams or overlaps.
+func (er *Renderer) SetSnapPixels(snap bool) {
+	er.SnapPixels = snap
+}
+
+// SetExtrude repeats the border pixels of each tile of the tileset images n pixels outwards, so filtering
+// and fractional positions sample the tile itself rather than its neighbours. Tiles packed in an atlas
+// use the extrusion of the atlas.
+func (er *Renderer) SetExtrude(n int) {
+	er.Flush()
+	er.Extrude = n
+	er.extruded = make(map[string]*atlasPages)
+}
+
+// extrudedTileset returns the tiles of the tileset image with their borders extruded, laid out like the
+// tileset on a page of their own. The page is drawn from the tileset image already loaded by the renderer.
+func (er *Renderer) extrudedTileset(ts *tsx.Tileset) (*atlasPages, error) {
+	// Tilesets embedded in a map have no source of their own
+	key := cmp.Or(ts.Source, ts.Image.Source)
+	if ap, ok := er.extruded[key]; ok {
+		return ap, nil
+	}
+
+	img, err := er.loadTilesetImage(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	n := er.Extrude
+	columns := ts.Columns
+	if columns == 0 {
+		columns = ts.Image.Width / (ts.TileWidth + ts.Spacing)
+	}
+	columns = max(columns, 1)
+	rows := (ts.TileCount + columns - 1) / columns
+
+	size := img.Bounds().Size().Add(image.Pt(columns*n*2, rows*n*2))
+	page := ebiten.NewImage(size.X, size.Y)
+	at := atlas.Tileset{
+		Name:       ts.Name,
+		Source:     ts.Source,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+	}
+	for id := range uint32(ts.TileCount) {
+		src, err := ts.GetTileRect(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tile rect for tile %d in tileset %s: %w", id, ts.Name, err)
+		}
+		// Tiles the image is too small for are left to the tileset image
+		if !src.In(img.Bounds()) {
+			continue
+		}
+
+		col, row := int(id)%columns, int(id)/columns
+		p := src.Min.Add(image.Pt(col*n*2+n, row*n*2+n))
+		extrudeTile(page, p, img, src, n)
+		at.Tiles = append(at.Tiles, atlas.Tile{ID: id, X: p.X, Y: p.Y, Width: src.Dx(), Height: src.Dy()})
+	}
+
+	a := &atlas.Atlas{
+		Extrude:  n,
+		Pages:    []atlas.Page{{Width: size.X, Height: size.Y}},
+		Tilesets: []atlas.Tileset{at},
+	}
+	ap := &atlasPages{atlas: a, images: []*ebiten.Image{page}}
+	er.extruded[key] = ap
+	return ap, nil
+}
+
+// extrudeTile draws the src tile of img to dst at p, stretching its border pixels n pixels outwards.
+func extrudeTile(dst *ebiten.Image, p image.Point, img *ebiten.Image, src image.Rectangle, n int) {
+	// A span is a source range drawn to size pixels at a destination
+	type span struct{ from, to, at, size int }
+	w, h := src.Dx(), src.Dy()
+	xs := []span{
+		{src.Min.X, src.Min.X + 1, p.X - n, n},
+		{src.Min.X, src.Max.X, p.X, w},
+		{src.Max.X - 1, src.Max.X, p.X + w, n},
+	}
+	ys := []span{
+		{src.Min.Y, src.Min.Y + 1, p.Y - n, n},
+		{src.Min.Y, src.Max.Y, p.Y, h},
+		{src.Max.Y - 1, src.Max.Y, p.Y + h, n},
+	}
+
+	for _, y := range ys {
+		for _, x := range xs {
+			part := img.SubImage(image.Rect(x.from, y.from, x.to, y.to)).(*ebiten.Image)
+			op := &ebiten.DrawImageOptions{Blend: ebiten.BlendCopy}
+			op.GeoM.Scale(float64(x.size)/float64(x.to-x.from), float64(y.size)/float64(y.to-y.from))
+			op.GeoM.Translate(float64(x.at), float64(y.at))
+			dst.DrawImage(part, op)
+		}
+	}
+}
+
+// SnapGeoM rounds the corners of a w x h image transformed by g to whole pixels. Transforms rotating the
+// image by other than quarter turns are left unchanged.
+func SnapGeoM(g *ebiten.GeoM, w, h float64) {
+	a, b, c, d := g.Element(0, 0), g.Element(0, 1), g.Element(1, 0), g.Element(1, 1)
+	if !(b == 0 && c == 0) && !(a == 0 && d == 0) {
+		return
+	}
+
+	round := func(x, y float64) (float64, float64) {
+		return math.Round(x), math.Round(y)
+	}
+	x0, y0 := round(g.Apply(0, 0))
+	xw, yw := round(g.Apply(w, 0))
+	xh, yh := round(g.Apply(0, h))
+
+	g.SetElement(0, 0, (xw-x0)/w)
+	g.SetElement(1, 0, (yw-y0)/w)
+	g.SetElement(0, 1, (xh-x0)/h)
+	g.SetElement(1, 1, (yh-y0)/h)
+	g.SetElement(0, 2, x0)
+	g.SetElement(1, 2, y0)
+}
+
+// PixelPerfect draws a scene at a fixed resolution to an offscreen image, then draws the image to the
+// screen scaled by the largest whole factor that fits, centred. Every pixel of the scene is the same size
+// on screen whatever the window size.
+//
+//	buffer := pp.Begin()
+//	tmxr.DrawMapLayer("town", "ground", &common.DrawOptions{Screen: buffer, Op: op})
+//	tsxr.Flush()
+//	pp.Present(screen)
+type PixelPerfect struct {
+	Width  int
+	Height int
+	// Background fills the screen around the scene, black when nil
+	Background color.Color
+
+	buffer *ebiten.Image
+	scale  int
+	offset image.Point
+}
+
+func NewPixelPerfect(width int, height int) *PixelPerfect {
+	return &PixelPerfect{
+		Width:  width,
+		Height: height,
+		scale:  1,
+	}
+}
+
+// Begin returns the cleared offscreen image to draw the scene on.
+func (pp *PixelPerfect) Begin() *ebiten.Image {
+	if pp.buffer == nil || pp.buffer.Bounds().Dx() != pp.Width || pp.buffer.Bounds().Dy() != pp.Height {
+		pp.buffer = ebiten.NewImage(pp.Width, pp.Height)
+	}
+	pp.buffer.Clear()
+	return pp.buffer
+}
+
+// Present draws the scene to the screen. Tiles queued by a batching renderer must be flushed first.
+func (pp *PixelPerfect) Present(screen *ebiten.Image) {
+	if pp.buffer == nil {
+		return
+	}
+
+	b := screen.Bounds()
+	pp.scale = max(1, min(b.Dx()/pp.Width, b.Dy()/pp.Height))
+	pp.offset = image.Pt((b.Dx()-pp.Width*pp.scale)/2, (b.Dy()-pp.Height*pp.scale)/2)
+
+	if pp.Background != nil {
+		screen.Fill(pp.Background)
+	} else {
+		screen.Fill(color.Black)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(pp.scale), float64(pp.scale))
+	op.GeoM.Translate(float64(pp.offset.X), float64(pp.offset.Y))
+	op.Filter = ebiten.FilterNearest
+	screen.DrawImage(pp.buffer, op)
+}
+
+// Scale returns the factor the scene was scaled by when last presented.
+func (pp *PixelPerfect) Scale() int {
+	return pp.scale
+}
+
+// ScreenToScene converts a screen position, eg. of the cursor, to a position in the scene.
+func (pp *PixelPerfect) ScreenToScene(x int, y int) (int, int) {
+	return floorDiv(x-pp.offset.X, pp.scale), floorDiv(y-pp.offset.Y, pp.scale)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,49 @@
+package renderer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestSnapGeoM(t *testing.T) {
+	geoM := func(a, b, c, d, tx, ty float64) ebiten.GeoM {
+		var g ebiten.GeoM
+		g.SetElement(0, 0, a)
+		g.SetElement(0, 1, b)
+		g.SetElement(1, 0, c)
+		g.SetElement(1, 1, d)
+		g.SetElement(0, 2, tx)
+		g.SetElement(1, 2, ty)
+		return g
+	}
+	rotated := geoM(1, 0, 0, 1, 10.4, 0.4)
+	rotated.Rotate(math.Pi / 4)
+
+	tests := []struct {
+		name string
+		g    ebiten.GeoM
+		want ebiten.GeoM
+	}{
+		{"translate", geoM(1, 0, 0, 1, 10.4, 0.6), geoM(1, 0, 0, 1, 10, 1)},
+		{"scale", geoM(1.5, 0, 0, 1.5, 0.3, 0.3), geoM(1.5, 0, 0, 1.5, 0, 0)},
+		{"scale to whole pixels", geoM(1.3, 0, 0, 1.3, 10.2, 10.2), geoM(21.0/16, 0, 0, 21.0/16, 10, 10)},
+		{"flip", geoM(-1, 0, 0, 1, 16.4, 0), geoM(-1, 0, 0, 1, 16, 0)},
+		{"quarter turn", geoM(0, -1, 1, 0, 10.4, 0.4), geoM(0, -1, 1, 0, 10, 0)},
+		{"other rotations are unchanged", rotated, rotated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.g
+			SnapGeoM(&g, 16, 16)
+			for i := range 2 {
+				for j := range 3 {
+					if got, want := g.Element(i, j), tt.want.Element(i, j); math.Abs(got-want) > 1e-9 {
+						t.Errorf("element %d,%d: got %v, want %v", i, j, got, want)
+					}
+				}
+			}
+		})
+	}
+}
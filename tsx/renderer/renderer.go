@@ -1,12 +1,12 @@
 package renderer
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/pkg/errors"
+	"github.com/talvor/tiled/clock"
 	"github.com/talvor/tiled/common"
 	"github.com/talvor/tiled/tsx"
 	"github.com/talvor/tiled/tsx/manager"
@@ -15,13 +15,59 @@ import (
 type Renderer struct {
 	TilesetManager  *manager.TilesetManager
 	TilesetImageMap map[string]*ebiten.Image
+	// Clock drives animated tiles and sprites, it defaults to clock.Default
+	Clock clock.Clock
+	// Palettes registered by name, see RegisterPalette and LoadPalettes
+	Palettes map[string]*Palette
+	// SnapPixels rounds tile edges to whole screen pixels, see SetSnapPixels
+	SnapPixels bool
+	// Extrude is the border added around the tiles of tileset images, see SetExtrude
+	Extrude int
+
+	tileAnimationOverrides map[tileKey]TileAnimationOverride
+	paletteImages          map[paletteKey]*ebiten.Image
+	batch                  *batch
+	canvasImages           map[string]*ebiten.Image
+	atlases                []*atlasPages
+	extruded               map[string]*atlasPages
+}
+
+// TileAnimationOverride changes the playback of an animated tile. Every instance of the tile
+// uses the same override so they stay in sync.
+type TileAnimationOverride struct {
+	// Speed scales the playback rate, 1 (or 0) is normal speed
+	Speed float64
+	// Phase shifts the animation forward
+	Phase time.Duration
+}
+
+type tileKey struct {
+	tileset string
+	tileID  uint32
 }
 
 func NewRenderer(tm *manager.TilesetManager) *Renderer {
-	return &Renderer{
+	er := &Renderer{
 		TilesetManager:  tm,
 		TilesetImageMap: make(map[string]*ebiten.Image),
+		Clock:           clock.Default,
+		Palettes:        make(map[string]*Palette),
+
+		tileAnimationOverrides: make(map[tileKey]TileAnimationOverride),
+		paletteImages:          make(map[paletteKey]*ebiten.Image),
+		canvasImages:           make(map[string]*ebiten.Image),
+		extruded:               make(map[string]*atlasPages),
+	}
+
+	// Atlases found by the tileset manager are used transparently
+	for _, a := range tm.Atlases {
+		er.UseAtlas(a)
 	}
+	return er
+}
+
+func (er *Renderer) SetClock(c clock.Clock) {
+	er.Clock = c
 }
 
 func (er *Renderer) MakeSprite(tileset interface{}) SpriteDrawer {
@@ -42,6 +88,7 @@ func (er *Renderer) DrawTilesetByName(name string, screen *ebiten.Image, op *ebi
 		return err
 	}
 
+	er.Flush()
 	screen.DrawImage(img, op)
 
 	return nil
@@ -66,35 +113,36 @@ func (er *Renderer) DrawTileWithName(tilesetName string, tileId uint32, opts *co
 	return er.DrawTile(ts, tileId, opts)
 }
 
-func (er *Renderer) DrawAnimatedTile(ts *tsx.Tileset, tileId uint32, opts *common.DrawOptions) error {
-	anim, err := ts.GetTileAnimation(tileId)
-	if err != nil {
-		return err
-	}
-
-	duration := anim.Frames[0].Duration
+// SetTileAnimationOverride sets the speed and phase of an animated tile of the named tileset.
+func (er *Renderer) SetTileAnimationOverride(tilesetName string, tileId uint32, override TileAnimationOverride) {
+	er.tileAnimationOverrides[tileKey{tilesetName, tileId}] = override
+}
 
-	animationIdx := int(time.Now().UnixMilli()) / int(duration) % len(anim.Frames)
-	frame := anim.Frames[animationIdx]
-	return er.drawTile(ts, frame.ID, opts)
+func (er *Renderer) ClearTileAnimationOverride(tilesetName string, tileId uint32) {
+	delete(er.tileAnimationOverrides, tileKey{tilesetName, tileId})
 }
 
-func (er *Renderer) drawTile(ts *tsx.Tileset, tileId uint32, opts *common.DrawOptions) error {
-	img, err := er.loadTilesetImage(ts)
-	if err != nil {
-		return err
-	}
+// DrawAnimatedTile draws the frame of the tile animation for the renderer clock. All instances of a tile
+// read the same clock, so they show the same frame.
+func (er *Renderer) DrawAnimatedTile(ts *tsx.Tileset, tileId uint32, opts *common.DrawOptions) error {
+	return er.drawTile(ts, er.animatedTileID(ts, tileId), opts)
+}
 
-	rect, err := ts.GetTileRect(tileId)
-	if err != nil {
-		return fmt.Errorf("failed to get tile rect for tile %d in tileset %s: %w", tileId, ts.Name, err)
+// animatedTileID returns the tile shown by the animation of the tile for the renderer clock.
+func (er *Renderer) animatedTileID(ts *tsx.Tileset, tileId uint32) uint32 {
+	t := er.Clock.Now()
+	if override, ok := er.tileAnimationOverrides[tileKey{ts.Name, tileId}]; ok {
+		if override.Speed != 0 {
+			t = time.Duration(float64(t) * override.Speed)
+		}
+		t += override.Phase
 	}
 
-	img = transformImage(img.SubImage(rect).(*ebiten.Image), opts)
-
-	opts.Screen.DrawImage(img, opts.Op)
+	return ts.AnimationFrameAt(tileId, t)
+}
 
-	return nil
+func (er *Renderer) drawTile(ts *tsx.Tileset, tileId uint32, opts *common.DrawOptions) error {
+	return er.drawTileWithPalette(ts, tileId, nil, opts)
 }
 
 func (er *Renderer) loadTilesetImage(ts *tsx.Tileset) (*ebiten.Image, error) {
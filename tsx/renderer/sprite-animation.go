@@ -4,7 +4,9 @@ import (
 	"errors"
 	"time"
 
+	"github.com/talvor/tiled/clock"
 	"github.com/talvor/tiled/common"
+	"github.com/talvor/tiled/tsx"
 )
 
 var ErrFrameTimingMismatch = errors.New("frame and timing slices must be the same length")
@@ -24,14 +26,55 @@ type Frame struct {
 }
 
 type animation struct {
-	sprite        SpriteDrawer
-	frames        []Frame
-	defaults      *AnimationDefaults
-	currentFrame  int
-	nextFrameTime int64
+	sprite   SpriteDrawer
+	frames   []Frame
+	defaults *AnimationDefaults
+	// timeline has the durations of frames, the id of each of its frames is the index in frames
+	timeline tsx.Animation
+	// clock is set by SetClock, nil follows the renderer of the sprite
+	clock        clock.Clock
+	currentFrame int
+	// start is the time on startClock the animation started at
+	start      time.Duration
+	startClock clock.Clock
+}
+
+// SetClock sets the clock driving the animation. By default the animation follows the clock of the
+// renderer drawing its sprite, so Renderer.SetClock pauses, scales and steps it too. Nil restores the default.
+// The animation restarts from its first frame on the new clock.
+func (a *animation) SetClock(c clock.Clock) {
+	a.clock = c
+	a.startClock = nil
+}
+
+func (a *animation) setFrames(frames []Frame) {
+	a.frames = frames
+	a.timeline.Frames = make([]tsx.Frame, len(frames))
+	for i, f := range frames {
+		a.timeline.Frames[i] = tsx.Frame{ID: uint32(i), Duration: int(f.duration)}
+	}
+}
+
+// rendererSprite is implemented by the sprites drawn by a Renderer.
+type rendererSprite interface {
+	renderer() *Renderer
+}
+
+// currentClock returns the clock driving the animation.
+func (a *animation) currentClock() clock.Clock {
+	if a.clock != nil {
+		return a.clock
+	}
+	if s, ok := a.sprite.(rendererSprite); ok && s.renderer() != nil && s.renderer().Clock != nil {
+		return s.renderer().Clock
+	}
+	return clock.Default
 }
 
 func (a *animation) DrawAnimation(opts *common.DrawOptions) error {
+	if len(a.frames) == 0 {
+		return nil
+	}
 	a.applyDefaults(opts)
 	a.determineFrame()
 
@@ -49,24 +92,16 @@ func (a *animation) applyDefaults(opts *common.DrawOptions) {
 	}
 }
 
+// determineFrame picks the frame shown at the time elapsed on the clock since the animation started, so
+// sparse draws and large clock steps skip the frames they missed. The animation starts on its first draw
+// and again when its clock changes.
 func (a *animation) determineFrame() {
-	if a.nextFrameTime == 0 {
-		a.currentFrame = 0
-		a.setNextFrameTime()
-		return
-	}
-	if time.Now().UnixMilli() >= a.nextFrameTime {
-		a.currentFrame++
-		if a.currentFrame >= len(a.frames) {
-			a.currentFrame = 0
-		}
-		a.setNextFrameTime()
+	c := a.currentClock()
+	if a.startClock != c {
+		a.startClock = c
+		a.start = c.Now()
 	}
-}
-
-func (a *animation) setNextFrameTime() {
-	frame := a.frames[a.currentFrame]
-	a.nextFrameTime = time.Now().UnixMilli() + int64(frame.duration)
+	a.currentFrame = int(a.timeline.FrameAt(c.Now() - a.start).ID)
 }
 
 type SimpleAnimation struct {
@@ -75,20 +110,19 @@ type SimpleAnimation struct {
 }
 
 func NewSimpleAnimation(sprite SpriteDrawer, duration uint32, frames []int, defaults *AnimationDefaults) *SimpleAnimation {
-	timedFrames := makeTimedFrames(frames, duration)
-	return &SimpleAnimation{
+	sa := &SimpleAnimation{
 		animation: animation{
-			sprite:       sprite,
-			frames:       timedFrames,
-			defaults:     defaults,
-			currentFrame: 0,
+			sprite:   sprite,
+			defaults: defaults,
 		},
 		duration: duration,
 	}
+	sa.setFrames(makeTimedFrames(frames, duration))
+	return sa
 }
 
 func (sa *SimpleAnimation) SetFrames(frames []int) {
-	sa.frames = makeTimedFrames(frames, sa.duration)
+	sa.setFrames(makeTimedFrames(frames, sa.duration))
 }
 
 type TimedAnimation struct {
@@ -108,14 +142,14 @@ func NewTimedAnimation(sprite SpriteDrawer, frames []int, timing []uint32, defau
 		}
 	}
 
-	return &TimedAnimation{
+	ta := &TimedAnimation{
 		animation: animation{
-			sprite:       sprite,
-			frames:       timedFrames,
-			defaults:     defaults,
-			currentFrame: 0,
+			sprite:   sprite,
+			defaults: defaults,
 		},
-	}, nil
+	}
+	ta.setFrames(timedFrames)
+	return ta, nil
 }
 
 func makeTimedFrames(frames []int, duration uint32) []Frame {
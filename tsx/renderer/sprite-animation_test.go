@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/talvor/tiled/clock"
+)
+
+func TestSpriteAnimationFollowsRendererClock(t *testing.T) {
+	c := clock.NewManual(100 * time.Millisecond)
+	r := &Renderer{Clock: c}
+	sa := NewSimpleAnimation(NewSimpleSprite("hero", r), 100, []int{1, 2, 3}, nil)
+
+	sa.determineFrame()
+	c.Step()
+	sa.determineFrame()
+	if sa.currentFrame != 1 {
+		t.Fatalf("after one step: frame %d, want 1", sa.currentFrame)
+	}
+
+	c.Pause()
+	c.Step()
+	sa.determineFrame()
+	if sa.currentFrame != 1 {
+		t.Errorf("renderer clock paused: frame %d, want 1", sa.currentFrame)
+	}
+}
+
+func TestSpriteAnimationOwnClock(t *testing.T) {
+	rendererClock := clock.NewManual(100 * time.Millisecond)
+	own := clock.NewManual(100 * time.Millisecond)
+	sa := NewSimpleAnimation(NewSimpleSprite("hero", &Renderer{Clock: rendererClock}), 100, []int{1, 2, 3}, nil)
+	sa.SetClock(own)
+
+	sa.determineFrame()
+	rendererClock.Step()
+	sa.determineFrame()
+	if sa.currentFrame != 0 {
+		t.Errorf("renderer clock moved the animation to frame %d", sa.currentFrame)
+	}
+
+	own.Step()
+	sa.determineFrame()
+	if sa.currentFrame != 1 {
+		t.Errorf("own clock: frame %d, want 1", sa.currentFrame)
+	}
+}
+
+func TestSpriteAnimationSkipsMissedFrames(t *testing.T) {
+	c := clock.NewManual(100 * time.Millisecond)
+	ta, err := NewTimedAnimation(NewSimpleSprite("hero", &Renderer{Clock: c}), []int{1, 2, 3}, []uint32{100, 50, 150}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta.determineFrame()
+	steps := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{120 * time.Millisecond, 1},
+		// Past the second frame in a single step
+		{100 * time.Millisecond, 2},
+		// Back to the start of the loop
+		{140 * time.Millisecond, 0},
+		// Three whole loops and on to the second frame
+		{3*300*time.Millisecond + 50*time.Millisecond, 1},
+	}
+	for i, step := range steps {
+		c.Advance(step.advance)
+		ta.determineFrame()
+		if ta.currentFrame != step.want {
+			t.Errorf("step %d at %v: frame %d, want %d", i, c.Now(), ta.currentFrame, step.want)
+		}
+	}
+}
+
+func TestSpriteAnimationRestartsOnNewClock(t *testing.T) {
+	late := clock.NewManual(100 * time.Millisecond)
+	late.Advance(time.Hour)
+	r := &Renderer{Clock: late}
+	sa := NewSimpleAnimation(NewSimpleSprite("hero", r), 100, []int{1, 2, 3}, nil)
+	sa.determineFrame()
+
+	early := clock.NewManual(100 * time.Millisecond)
+	r.Clock = early
+	sa.determineFrame()
+	early.Step()
+	sa.determineFrame()
+	if sa.currentFrame != 1 {
+		t.Errorf("renderer clock swapped: frame %d, want 1", sa.currentFrame)
+	}
+}
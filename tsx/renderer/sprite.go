@@ -3,7 +3,6 @@ package renderer
 import (
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/talvor/tiled/common"
@@ -39,23 +38,30 @@ func NewSimpleSprite(tileSet string, renderer *Renderer) *SimpleSprite {
 func (ss *SimpleSprite) Draw(id interface{}, opts *common.DrawOptions) error {
 	switch id.(type) {
 	case int:
-		return drawSpriteByID(ss.Tileset, uint32(id.(int)), ss.Renderer, opts)
+		return drawSpriteByID(ss.Tileset, uint32(id.(int)), nil, ss.Renderer, opts)
 	case uint32:
-		return drawSpriteByID(ss.Tileset, id.(uint32), ss.Renderer, opts)
+		return drawSpriteByID(ss.Tileset, id.(uint32), nil, ss.Renderer, opts)
 	case string:
-		return drawSpriteByName(ss.Tileset, id.(string), ss.Renderer, opts)
+		return drawSpriteByName(ss.Tileset, id.(string), nil, ss.Renderer, opts)
 	}
 	return fmt.Errorf("invalid id type: %w", ErrInvalidIdType)
 }
 
+func (ss *SimpleSprite) renderer() *Renderer {
+	return ss.Renderer
+}
+
 func (ss *SimpleSprite) DrawWithAnimation(name string, duration int, opts *common.DrawOptions) error {
-	return drawSpriteWithAnimation(ss.Tileset, name, duration, ss.Renderer, opts)
+	return drawSpriteWithAnimation(ss.Tileset, name, duration, nil, ss.Renderer, opts)
 }
 
 // A compound sprite is a sprite that is made up of multiple tilesets
 // and can be drawn with a single tile id
 type CompoundSprite struct {
 	Tilesets []string
+	// Palettes recolor the part drawn from the tileset at the same index, optional. A tileset can be
+	// used by several parts with different palettes
+	Palettes []*Palette
 	Renderer *Renderer
 }
 
@@ -69,20 +75,20 @@ func NewCompoundSprite(tileSets []string, renderer *Renderer) *CompoundSprite {
 func (cs *CompoundSprite) Draw(id interface{}, opts *common.DrawOptions) error {
 	switch id.(type) {
 	case int:
-		for _, tileset := range cs.Tilesets {
-			if err := drawSpriteByID(tileset, uint32(id.(int)), cs.Renderer, opts); err != nil {
+		for i, tileset := range cs.Tilesets {
+			if err := drawSpriteByID(tileset, uint32(id.(int)), cs.palette(i), cs.Renderer, opts); err != nil {
 				return err
 			}
 		}
 	case uint32:
-		for _, tileset := range cs.Tilesets {
-			if err := drawSpriteByID(tileset, id.(uint32), cs.Renderer, opts); err != nil {
+		for i, tileset := range cs.Tilesets {
+			if err := drawSpriteByID(tileset, id.(uint32), cs.palette(i), cs.Renderer, opts); err != nil {
 				return err
 			}
 		}
 	case string:
-		for _, tileset := range cs.Tilesets {
-			if err := drawSpriteByName(tileset, id.(string), cs.Renderer, opts); err != nil {
+		for i, tileset := range cs.Tilesets {
+			if err := drawSpriteByName(tileset, id.(string), cs.palette(i), cs.Renderer, opts); err != nil {
 				return err
 			}
 		}
@@ -92,9 +98,21 @@ func (cs *CompoundSprite) Draw(id interface{}, opts *common.DrawOptions) error {
 	return nil
 }
 
+func (cs *CompoundSprite) renderer() *Renderer {
+	return cs.Renderer
+}
+
+// palette returns the palette of the part, nil when it has none.
+func (cs *CompoundSprite) palette(part int) *Palette {
+	if part < len(cs.Palettes) {
+		return cs.Palettes[part]
+	}
+	return nil
+}
+
 func (cs *CompoundSprite) DrawWithAnimation(name string, duration int, opts *common.DrawOptions) error {
-	for _, tileset := range cs.Tilesets {
-		if err := drawSpriteWithAnimation(tileset, name, duration, cs.Renderer, opts); err != nil {
+	for i, tileset := range cs.Tilesets {
+		if err := drawSpriteWithAnimation(tileset, name, duration, cs.palette(i), cs.Renderer, opts); err != nil {
 			return err
 		}
 	}
@@ -119,6 +137,10 @@ func NewComplexSprite(tileSet string, columns int, renderer *Renderer) *ComplexS
 	}
 }
 
+func (cs *ComplexSprite) renderer() *Renderer {
+	return cs.Renderer
+}
+
 func (cs *ComplexSprite) AddPart(id uint32, parts []uint32) {
 	cs.Parts[id] = parts
 }
@@ -155,7 +177,7 @@ func (cs *ComplexSprite) Draw(id interface{}, opts *common.DrawOptions) error {
 
 	dx := float64(0)
 	for idx, part := range parts {
-		if err := drawSpriteByID(cs.Tileset, part, cs.Renderer, drawOptions); err != nil {
+		if err := drawSpriteByID(cs.Tileset, part, nil, cs.Renderer, drawOptions); err != nil {
 			return err
 		}
 		if (idx+1)%cs.Columns == 0 {
@@ -176,6 +198,7 @@ func getTileset(tileset string, tilesetManager *manager.TilesetManager) *tsx.Til
 func drawSpriteByID(
 	tileset string,
 	ID uint32,
+	palette *Palette,
 	renderer *Renderer,
 	opts *common.DrawOptions,
 ) error {
@@ -184,49 +207,46 @@ func drawSpriteByID(
 		return fmt.Errorf("failed to find tileset with name %s: %w", tileset, ErrTileset)
 	}
 
-	img, err := renderer.loadTilesetImage(ts)
+	img, rect, err := renderer.tileImage(ts, ID, palette)
 	if err != nil {
-		return fmt.Errorf("failed to load tileset image for tileset %s: %w", tileset, ErrTileset)
+		return fmt.Errorf("failed to get tile %d in tileset %s: %w", ID, tileset, ErrTileset)
 	}
 
-	rect, err := ts.GetTileRect(ID)
-	if err != nil {
-		return fmt.Errorf("failed to get tile rect for tile %d in tileset %s: %w", ID, tileset, ErrTileset)
-	}
-
-	img = transformImage(img.SubImage(rect).(*ebiten.Image), opts)
-
-	opts.Screen.DrawImage(img, opts.Op)
+	renderer.drawImage(opts.Screen, img, rect, flipOptions(rect.Dx(), rect.Dy(), opts))
 
 	return nil
 }
 
-func drawSpriteByName(tileset string, name string, er *Renderer, opts *common.DrawOptions) error {
+func drawSpriteByName(tileset string, name string, palette *Palette, er *Renderer, opts *common.DrawOptions) error {
 	tile, err := getTileByName(tileset, name, er)
 	if err != nil {
 		return err
 	}
 
-	return drawSpriteByID(tileset, tile.ID, er, opts)
+	return drawSpriteByID(tileset, tile.ID, palette, er, opts)
 }
 
-func drawSpriteWithAnimation(tileset string, name string, duration int, er *Renderer, opts *common.DrawOptions) error {
+// drawSpriteWithAnimation draws the frame of the named animated tile for the renderer clock, using the
+// durations of the tileset frames and the tile animation overrides like DrawAnimatedTile. The duration is
+// only used for animations whose frames have none.
+func drawSpriteWithAnimation(tileset string, name string, duration int, palette *Palette, er *Renderer, opts *common.DrawOptions) error {
 	tile, err := getTileByName(tileset, name, er)
 	if err != nil {
 		return err
 	}
 
-	if tile.Animation.Frames == nil || len(tile.Animation.Frames) == 0 {
+	if len(tile.Animation.Frames) == 0 {
 		return fmt.Errorf("no animation frames found for tile %s in tileset %s: %w", name, tileset, ErrNoAmimationFrames)
 	}
 
-	tileID := tile.ID
-	if tile.Animation.Frames != nil && len(tile.Animation.Frames) > 0 {
-		animationIdx := int(time.Now().UnixMilli()) / duration % len(tile.Animation.Frames)
-		frame := tile.Animation.Frames[animationIdx]
-		tileID = frame.ID
+	var tileID uint32
+	if tile.Animation.Duration() <= 0 && duration > 0 {
+		animationIdx := int(er.Clock.Now().Milliseconds()) / duration % len(tile.Animation.Frames)
+		tileID = tile.Animation.Frames[animationIdx].ID
+	} else {
+		tileID = er.animatedTileID(er.TilesetManager.GetTilesetByName(tileset), tile.ID)
 	}
-	return drawSpriteByID(tileset, tileID, er, opts)
+	return drawSpriteByID(tileset, tileID, palette, er, opts)
 }
 
 func getTileByName(tileset string, name string, er *Renderer) (*tsx.Tile, error) {
@@ -243,23 +263,32 @@ func getTileByName(tileset string, name string, er *Renderer) (*tsx.Tile, error)
 	return tile, nil
 }
 
-func transformImage(img *ebiten.Image, opts *common.DrawOptions) *ebiten.Image {
+// flipOptions returns the options to draw a tile of size w x h flipped around its centre, followed by the
+// transform of opts. Diagonal flips swap the axes first, like flipped tiles in maps.
+func flipOptions(w, h int, opts *common.DrawOptions) *ebiten.DrawImageOptions {
+	if !opts.FlipHorizontal && !opts.FlipVertical && !opts.FlipDiagonal {
+		return opts.Op
+	}
+
+	op := *opts.Op
+	op.GeoM.Reset()
+	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	if opts.FlipDiagonal {
+		var transpose ebiten.GeoM
+		transpose.SetElement(0, 0, 0)
+		transpose.SetElement(0, 1, 1)
+		transpose.SetElement(1, 0, 1)
+		transpose.SetElement(1, 1, 0)
+		op.GeoM.Concat(transpose)
+		w, h = h, w
+	}
 	if opts.FlipHorizontal {
-		result := ebiten.NewImage(img.Bounds().Dx(), img.Bounds().Dy())
-		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Scale(-1, 1)
-		op.GeoM.Translate(float64(img.Bounds().Dx()), 0)
-		result.DrawImage(img, op)
-		img = result
 	}
 	if opts.FlipVertical {
-		result := ebiten.NewImage(img.Bounds().Dx(), img.Bounds().Dy())
-		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Scale(1, -1)
-		op.GeoM.Translate(0, float64(img.Bounds().Dy()))
-		result.DrawImage(img, op)
-		img = result
 	}
-
-	return img
+	op.GeoM.Translate(float64(w)/2, float64(h)/2)
+	op.GeoM.Concat(opts.Op.GeoM)
+	return &op
 }
@@ -0,0 +1,86 @@
+package renderer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/talvor/tiled/common"
+)
+
+// transformImage is how flipped sprites were drawn before flipOptions, copying the tile into a new
+// image for every flip. It is kept to compare the two in the benchmarks.
+func transformImage(img *ebiten.Image, opts *common.DrawOptions) *ebiten.Image {
+	if opts.FlipHorizontal {
+		result := ebiten.NewImage(img.Bounds().Dx(), img.Bounds().Dy())
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(-1, 1)
+		op.GeoM.Translate(float64(img.Bounds().Dx()), 0)
+		result.DrawImage(img, op)
+		img = result
+	}
+	if opts.FlipVertical {
+		result := ebiten.NewImage(img.Bounds().Dx(), img.Bounds().Dy())
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(1, -1)
+		op.GeoM.Translate(0, float64(img.Bounds().Dy()))
+		result.DrawImage(img, op)
+		img = result
+	}
+
+	return img
+}
+
+func TestFlipOptions(t *testing.T) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(100, 50)
+
+	tests := []struct {
+		name                 string
+		horizontal, vertical bool
+		diagonal             bool
+		x, y                 float64 // where the top left pixel of a 16x8 tile ends up
+	}{
+		{"none", false, false, false, 100, 50},
+		{"horizontal", true, false, false, 116, 50},
+		{"vertical", false, true, false, 100, 58},
+		{"both", true, true, false, 116, 58},
+		{"diagonal", false, false, true, 100, 50},
+	}
+	for _, tt := range tests {
+		got := flipOptions(16, 8, &common.DrawOptions{Op: op, FlipHorizontal: tt.horizontal, FlipVertical: tt.vertical, FlipDiagonal: tt.diagonal})
+		x, y := got.GeoM.Apply(0, 0)
+		if x != tt.x || y != tt.y {
+			t.Errorf("%s: top left at %v,%v, want %v,%v", tt.name, x, y, tt.x, tt.y)
+		}
+	}
+
+	// The transform of the options is untouched
+	if x, y := op.GeoM.Apply(0, 0); x != 100 || y != 50 {
+		t.Errorf("options moved to %v,%v", x, y)
+	}
+}
+
+func benchmarkFlip(b *testing.B, draw func(dst, tile *ebiten.Image, opts *common.DrawOptions)) {
+	sheet := ebiten.NewImage(256, 256)
+	tile := sheet.SubImage(image.Rect(16, 16, 32, 32)).(*ebiten.Image)
+	dst := ebiten.NewImage(320, 240)
+	opts := &common.DrawOptions{Op: &ebiten.DrawImageOptions{}, FlipHorizontal: true, FlipVertical: true}
+
+	b.ReportAllocs()
+	for range b.N {
+		draw(dst, tile, opts)
+	}
+}
+
+func BenchmarkFlipTransformImage(b *testing.B) {
+	benchmarkFlip(b, func(dst, tile *ebiten.Image, opts *common.DrawOptions) {
+		dst.DrawImage(transformImage(tile, opts), opts.Op)
+	})
+}
+
+func BenchmarkFlipOptions(b *testing.B) {
+	benchmarkFlip(b, func(dst, tile *ebiten.Image, opts *common.DrawOptions) {
+		dst.DrawImage(tile, flipOptions(tile.Bounds().Dx(), tile.Bounds().Dy(), opts))
+	})
+}
@@ -2,14 +2,17 @@ package tsx
 
 import (
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"path"
+	"time"
 )
 
 var (
 	ErrTileTypeNotFound  = errors.New("tsx: tile type not found")
 	ErrTileIDOutOfBounds = errors.New("tsx: tile id out of bounds")
+	ErrPropertyNotFound  = errors.New("tsx: property not found")
 )
 
 type Tileset struct {
@@ -111,6 +114,17 @@ func (ts *Tileset) GetTileAnimation(tileID uint32) (*Animation, error) {
 	return nil, ErrTileIDOutOfBounds
 }
 
+// AnimationFrameAt returns the tile shown at time t by the animation of the tile, the tile itself when it
+// is not animated.
+func (ts *Tileset) AnimationFrameAt(tileID uint32, t time.Duration) uint32 {
+	for _, tile := range ts.Tiles {
+		if tile.ID == tileID && len(tile.Animation.Frames) > 0 {
+			return tile.Animation.FrameAt(t).ID
+		}
+	}
+	return tileID
+}
+
 func (ts *Tileset) decodeImage() {
 	if ts.Image.Source == "" {
 		return
@@ -130,6 +144,7 @@ type Image struct {
 type Tile struct {
 	ID           uint32         `xml:"id,attr"`
 	Type         string         `xml:"type,attr"`
+	Properties   Properties     `xml:"properties>property"`
 	Animation    Animation      `xml:"animation"`
 	ObjectGroups []*ObjectGroup `xml:"objectgroup"`
 }
@@ -138,6 +153,41 @@ type Animation struct {
 	Frames []Frame `xml:"frame"`
 }
 
+// Duration returns the time taken to play every frame once.
+func (a *Animation) Duration() time.Duration {
+	var total time.Duration
+	for _, f := range a.Frames {
+		total += time.Duration(f.Duration) * time.Millisecond
+	}
+	return total
+}
+
+// FrameAt returns the frame shown at time t of the looping animation, walking the duration of each frame.
+// An animation without frames returns the zero Frame.
+func (a *Animation) FrameAt(t time.Duration) Frame {
+	if len(a.Frames) == 0 {
+		return Frame{}
+	}
+
+	total := a.Duration()
+	if total <= 0 {
+		return a.Frames[0]
+	}
+
+	t %= total
+	if t < 0 {
+		t += total
+	}
+	for _, f := range a.Frames {
+		d := time.Duration(f.Duration) * time.Millisecond
+		if t < d {
+			return f
+		}
+		t -= d
+	}
+	return a.Frames[len(a.Frames)-1]
+}
+
 type Frame struct {
 	ID       uint32 `xml:"tileid,attr"`
 	Duration int    `xml:"duration,attr"`
@@ -165,6 +215,15 @@ type HexColor struct {
 
 type Properties []*Property
 
+func (ps Properties) Get(name string) (*Property, error) {
+	for _, p := range ps {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("property: %s %w", name, ErrPropertyNotFound)
+}
+
 type Property struct {
 	Name  string `xml:"name,attr"`
 	Type  string `xml:"type,attr"`
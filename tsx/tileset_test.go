@@ -0,0 +1,31 @@
+package tsx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnimationFrameAt(t *testing.T) {
+	a := &Animation{Frames: []Frame{{ID: 4, Duration: 100}, {ID: 5, Duration: 50}, {ID: 6, Duration: 150}}}
+	still := &Animation{Frames: []Frame{{ID: 7}, {ID: 8}}}
+
+	tests := []struct {
+		name string
+		a    *Animation
+		t    time.Duration
+		want Frame
+	}{
+		{"first frame", a, 0, Frame{ID: 4, Duration: 100}},
+		{"second frame", a, 120 * time.Millisecond, Frame{ID: 5, Duration: 50}},
+		{"last frame", a, 299 * time.Millisecond, Frame{ID: 6, Duration: 150}},
+		{"looped", a, 420 * time.Millisecond, Frame{ID: 5, Duration: 50}},
+		{"before the start", a, -10 * time.Millisecond, Frame{ID: 6, Duration: 150}},
+		{"no duration", still, time.Second, Frame{ID: 7}},
+		{"no frames", &Animation{}, time.Second, Frame{}},
+	}
+	for _, tt := range tests {
+		if got := tt.a.FrameAt(tt.t); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}